@@ -2,16 +2,21 @@ package restful
 
 import (
 	"bytes"
+	"context"
 	"github.com/gin-gonic/gin"
 	"io"
 	"net/http"
 
 	"github.com/lookupearth/restful/response"
+	"gorm.io/gorm"
 )
 
 const (
 	ctxResource    string = "resource"
 	ctxRequestBody string = "requestBody"
+	ctxVersion     string = "apiVersion"
+	ctxTx          string = "tx"
+	ctxPartialData string = "partialData"
 )
 
 // ContextWithResource 将 Resource 设置到 ctx 里去，之后可以使用 ResourceFromContext 读取到
@@ -28,6 +33,45 @@ func ResourceFromContext(c *gin.Context) IResource {
 	return val.(IResource)
 }
 
+// ContextWithVersion 将接口版本设置到 ctx 里去，之后可以使用 VersionFromContext 读取到
+func ContextWithVersion(c *gin.Context, version string) {
+	c.Set(ctxVersion, version)
+}
+
+// VersionFromContext 从 ctx 里读取接口版本，未设置时返回空字符串
+func VersionFromContext(c *gin.Context) string {
+	return c.GetString(ctxVersion)
+}
+
+// ContextWithTx 将当前写操作的事务设置到 ctx 里去，事务内的校验等查询可以使用 TxFromContext 读取到
+//
+//	返回清除事务的函数，需在事务提交或回滚前调用，避免之后的查询使用已结束的事务
+func ContextWithTx(c *gin.Context, tx *gorm.DB) func() {
+	c.Set(ctxTx, tx)
+	return func() {
+		c.Set(ctxTx, (*gorm.DB)(nil))
+	}
+}
+
+// TxFromContext 从 ctx 里读取事务，未开启时返回nil
+func TxFromContext(c *gin.Context) *gorm.DB {
+	val, has := c.Get(ctxTx)
+	if !has {
+		return nil
+	}
+	return val.(*gorm.DB)
+}
+
+// DBContext 数据库操作使用的 context，为请求的 context
+//
+//	不能直接使用 *gin.Context：gin 会复用 Context，而 database/sql 在查询结束后仍可能在其他协程中读取 context
+func DBContext(c *gin.Context) context.Context {
+	if c == nil || c.Request == nil {
+		return context.Background()
+	}
+	return c.Request.Context()
+}
+
 // RequestBody 请求body，为了支持修改专门设置
 type RequestBody struct {
 	Have  bool
@@ -16,11 +16,16 @@ type logIDSetter interface {
 type Controller struct {
 	HaveDetail  bool
 	urlHandlers map[string]map[HttpMethod]HandlerFunc
+	// routes 初始化后的路由，同一个 Controller 可挂载到多个版本
+	routes map[string]gin.HandlerFunc
 
 	// init阶段初始化
 	instance interface{}
+	root     IRoot
 }
 
+var _ IRoutes = (*Controller)(nil)
+
 func NewController() *Controller {
 	return &Controller{
 		HaveDetail:  false,
@@ -31,10 +36,28 @@ func NewController() *Controller {
 // Init Resource 初始化
 func (ctrl *Controller) Init(instance interface{}, root IRoot) {
 	ctrl.instance = instance
+	ctrl.root = root
+}
+
+func (ctrl *Controller) recovery() *Recovery {
+	if root, ok := ctrl.root.(IRecoveryRoot); ok && root.GetRecovery() != nil {
+		return root.GetRecovery()
+	}
+	return defaultRecovery
 }
 
 // Mount 将 Resource 方法注册到路由
 func (ctrl *Controller) Mount(router *gin.RouterGroup, urlPath string) {
+	for path, handler := range ctrl.Routes() {
+		router.Any(urlPath+path, handler)
+	}
+}
+
+// Routes 初始化 Resource 方法并返回路由，key 为相对资源url的路径，只初始化一次
+func (ctrl *Controller) Routes() map[string]gin.HandlerFunc {
+	if ctrl.routes != nil {
+		return ctrl.routes
+	}
 	instance := ctrl.instance
 	var decorators []HandlerDecorator
 	decorative, ok := instance.(IDecorator)
@@ -91,20 +114,50 @@ func (ctrl *Controller) Mount(router *gin.RouterGroup, urlPath string) {
 		}
 		ctrl.RegisterMethod(ListMethod, HTTPMethodPost, "_search", search.Search)
 	}
+	stream, ok := instance.(IStream)
+	if ok {
+		if init, ok := instance.(IStreamInit); ok {
+			init.InitStream(instance.(IResource))
+		}
+		ctrl.RegisterMethod(ListMethod, HTTPMethodGet, "_stream", stream.Stream)
+	}
+	aggregate, ok := instance.(IAggregate)
+	if ok {
+		if init, ok := instance.(IAggregateInit); ok {
+			init.InitAggregate(instance.(IResource))
+		}
+		ctrl.RegisterMethod(ListMethod, HTTPMethodGet, "_aggregate", aggregate.Aggregate)
+	}
+	export, ok := instance.(IExport)
+	if ok {
+		if init, ok := instance.(IExportInit); ok {
+			init.InitExport(instance.(IResource))
+		}
+		ctrl.RegisterMethod(ListMethod, HTTPMethodGet, "_export", export.Export)
+	}
+	imp, ok := instance.(IImport)
+	if ok {
+		if init, ok := instance.(IImportInit); ok {
+			init.InitImport(instance.(IResource))
+		}
+		ctrl.RegisterMethod(ListMethod, HTTPMethodPost, "_import", imp.Import)
+	}
 
+	ctrl.routes = make(map[string]gin.HandlerFunc, len(ctrl.urlHandlers))
 	for path, methods := range ctrl.urlHandlers {
 		// 安装装饰器，RegisterMethod阶段还没完成Init，只能在这里处理
 		for method, handler := range methods {
 			methods[method] = InstallDecorators(handler, decorators)
 		}
-		// 操作方法注册到路由
-		router.Any(urlPath+path, func(c *gin.Context) {
-			res := ctrl.httpProxy(methods)(c)
+		proxy := ctrl.httpProxy(methods)
+		ctrl.routes[path] = func(c *gin.Context) {
+			res := proxy(c)
 			if res != nil {
 				res.Response(c)
 			}
-		})
+		}
 	}
+	return ctrl.routes
 }
 
 // RegisterMethod 操作方法和返回类型注册
@@ -151,16 +204,32 @@ func (ctrl *Controller) httpProxy(methods map[HttpMethod]HandlerFunc) HandlerFun
 				if err != nil {
 					return response.NewError(500, err)
 				}
+				// 版本请求数据转换为 Model 的json数据
+				if versioned, ok := resource.(IVersioned); ok {
+					rb := RequestBodyFromContext(c)
+					body, err := versioned.TransformRequest(c, rb.Get())
+					if err != nil {
+						return response.NewError(400, err)
+					}
+					rb.Set(body)
+				}
 			}
 		}
 		defer func() {
 			if r := recover(); r != nil {
-				err, ok := r.(*response.Error)
-				if ok {
-					err.Response(c)
-				} else {
-					panic(r)
+				// 支持包装后的 *response.Error，如 panic(fmt.Errorf("...: %w", restful.Forbidden("...")))
+				var e *response.Error
+				if err, ok := r.(error); ok {
+					e = response.AsError(err)
 				}
+				// 其余 panic 记录调用栈后返回500
+				if e == nil {
+					e = ctrl.recovery().Recover(c, r)
+				}
+				// panic 的可能是共享的错误变量，复制后再设置 logid
+				res := *e
+				res.SetLogID(c.GetString("logid"))
+				res.Response(c)
 			}
 		}()
 		var res Response
@@ -196,9 +265,17 @@ func (ctrl *Controller) httpProxy(methods map[HttpMethod]HandlerFunc) HandlerFun
 		}
 
 		if res == nil {
-			res = &response.TextResponse{
-				Status: 405,
-				Text:   "Method Not Allowed",
+			c.Header("Allow", strings.Join(append(GetMethodsName(methods), "OPTIONS"), ","))
+			res = response.CodeMethodNotAllowed.New("")
+		}
+		if versioned, ok := ctrl.instance.(IVersioned); ok {
+			if r, ok := res.(*response.Response); ok {
+				data, err := versioned.TransformResponse(c, r.Data)
+				if err != nil {
+					res = response.NewError(500, err)
+				} else {
+					r.Data = data
+				}
 			}
 		}
 		if setter, ok := res.(logIDSetter); ok {
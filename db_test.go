@@ -0,0 +1,29 @@
+package restful
+
+import (
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB 不连接数据库的 mysql DryRun 实例，用于校验生成的SQL
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "root:root@tcp(127.0.0.1:3306)/demo",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open fail, error=%v", err)
+	}
+	return db
+}
+
+// captureSQL 记录 db 执行查询生成的SQL，参数已代入
+func captureSQL(db *gorm.DB) *[]string {
+	sqls := make([]string, 0)
+	_ = db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	})
+	return &sqls
+}
@@ -0,0 +1,256 @@
+package restful
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/lookupearth/restful/model"
+	"github.com/lookupearth/restful/response"
+)
+
+// 数据库错误类型，见 ClassifyDBError
+const (
+	DBErrorDuplicate  = "duplicate"
+	DBErrorForeignKey = "foreign_key"
+	DBErrorCheck      = "check"
+	DBErrorRetryable  = "retryable"
+)
+
+// DBError 分类后的数据库错误，作为 response.Error.Data 返回，不包含原始的 SQL 信息
+type DBError struct {
+	Type string `json:"type"`
+	// Fields 出错的字段，CheckDBResult 中会转换为json字段，无法确定时为空
+	Fields []string `json:"fields,omitempty"`
+	// Constraint 约束或索引名
+	Constraint string `json:"constraint,omitempty"`
+	// Retryable 死锁、序列化失败等可以重试的错误
+	Retryable bool `json:"retryable"`
+}
+
+var (
+	mysqlDuplicateRe   = regexp.MustCompile("for key '(?:[^']*\\.)?([^'.]+)'")
+	mysqlForeignKeyRe  = regexp.MustCompile("CONSTRAINT `([^`]+)` FOREIGN KEY \\(([^)]+)\\)")
+	mysqlCheckRe       = regexp.MustCompile("[Cc]heck constraint '([^']+)'")
+	postgresKeyRe      = regexp.MustCompile(`Key \(([^)]+)\)=`)
+	sqliteConstraintRe = regexp.MustCompile(`(UNIQUE|FOREIGN KEY|CHECK) constraint failed(?:: (.+))?`)
+)
+
+// ClassifyDBError 识别 MySQL、Postgres、SQLite 的唯一键、外键、检查约束与死锁错误，无法识别时返回nil
+//
+//	不依赖具体驱动，通过 MySQLError.Number、PgError.Code（SQLSTATE）及 SQLite 的错误信息识别，
+//	同时支持开启 TranslateError 后 GORM 返回的 gorm.ErrDuplicatedKey 等错误。Fields 为db列名。
+func ClassifyDBError(err error) *DBError {
+	if err == nil {
+		return nil
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if number, ok := errorField(e, "Number").(uint16); ok {
+			return classifyMySQL(number, e.Error())
+		}
+		if code, ok := errorField(e, "Code").(string); ok && len(code) == 5 {
+			if dbErr := classifyPostgres(e, code); dbErr != nil {
+				return dbErr
+			}
+		}
+	}
+	if dbErr := classifySQLite(err.Error()); dbErr != nil {
+		return dbErr
+	}
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &DBError{Type: DBErrorDuplicate}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return &DBError{Type: DBErrorForeignKey}
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return &DBError{Type: DBErrorCheck}
+	}
+	return nil
+}
+
+// errorField 读取驱动错误结构体中的字段，字段类型为自定义字符串类型时转换为 string
+func errorField(err error, name string) interface{} {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	f := v.FieldByName(name)
+	if !f.IsValid() || !f.CanInterface() {
+		return nil
+	}
+	if f.Kind() == reflect.String {
+		return f.String()
+	}
+	return f.Interface()
+}
+
+func classifyMySQL(number uint16, msg string) *DBError {
+	switch number {
+	case 1062, 1586:
+		dbErr := &DBError{Type: DBErrorDuplicate}
+		if m := mysqlDuplicateRe.FindStringSubmatch(msg); m != nil {
+			dbErr.Constraint = m[1]
+		}
+		return dbErr
+	case 1216, 1217, 1451, 1452:
+		dbErr := &DBError{Type: DBErrorForeignKey}
+		if m := mysqlForeignKeyRe.FindStringSubmatch(msg); m != nil {
+			dbErr.Constraint = m[1]
+			dbErr.Fields = splitColumns(m[2])
+		}
+		return dbErr
+	case 3819:
+		dbErr := &DBError{Type: DBErrorCheck}
+		if m := mysqlCheckRe.FindStringSubmatch(msg); m != nil {
+			dbErr.Constraint = m[1]
+		}
+		return dbErr
+	case 1205, 1213:
+		return &DBError{Type: DBErrorRetryable, Retryable: true}
+	}
+	return nil
+}
+
+func classifyPostgres(err error, code string) *DBError {
+	var dbErr *DBError
+	switch code {
+	case "23505":
+		dbErr = &DBError{Type: DBErrorDuplicate}
+	case "23503":
+		dbErr = &DBError{Type: DBErrorForeignKey}
+	case "23514":
+		dbErr = &DBError{Type: DBErrorCheck}
+	case "40001", "40P01":
+		return &DBError{Type: DBErrorRetryable, Retryable: true}
+	default:
+		return nil
+	}
+	// pgconn.PgError 为 ConstraintName，lib/pq 为 Constraint
+	for _, name := range []string{"ConstraintName", "Constraint"} {
+		if constraint, ok := errorField(err, name).(string); ok && constraint != "" {
+			dbErr.Constraint = constraint
+			break
+		}
+	}
+	if detail, ok := errorField(err, "Detail").(string); ok {
+		if m := postgresKeyRe.FindStringSubmatch(detail); m != nil {
+			dbErr.Fields = splitColumns(m[1])
+		}
+	}
+	return dbErr
+}
+
+func classifySQLite(msg string) *DBError {
+	if strings.Contains(msg, "database is locked") || strings.Contains(msg, "database table is locked") {
+		return &DBError{Type: DBErrorRetryable, Retryable: true}
+	}
+	m := sqliteConstraintRe.FindStringSubmatch(msg)
+	if m == nil {
+		return nil
+	}
+	switch m[1] {
+	case "UNIQUE":
+		// UNIQUE constraint failed: users.tenant, users.email
+		dbErr := &DBError{Type: DBErrorDuplicate}
+		for _, column := range splitColumns(m[2]) {
+			if i := strings.LastIndex(column, "."); i >= 0 {
+				column = column[i+1:]
+			}
+			dbErr.Fields = append(dbErr.Fields, column)
+		}
+		return dbErr
+	case "FOREIGN KEY":
+		return &DBError{Type: DBErrorForeignKey}
+	default:
+		return &DBError{Type: DBErrorCheck, Constraint: strings.TrimSpace(m[2])}
+	}
+}
+
+func splitColumns(s string) []string {
+	columns := make([]string, 0)
+	for _, column := range strings.Split(s, ",") {
+		column = strings.Trim(strings.TrimSpace(column), "`\"")
+		if column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// jsonFields 将db列转换为json字段，唯一键未给出列名时通过索引名查找
+func (e *DBError) jsonFields(sch *schema.Schema) {
+	if sch == nil {
+		e.Fields = nil
+		return
+	}
+	columns := e.Fields
+	if len(columns) == 0 && e.Constraint != "" {
+		if e.Constraint == "PRIMARY" {
+			columns = sch.PrimaryFieldDBNames
+		} else if index, ok := sch.ParseIndexes()[e.Constraint]; ok {
+			for _, option := range index.Fields {
+				columns = append(columns, option.DBName)
+			}
+		} else if sch.LookUpField(e.Constraint) != nil {
+			columns = []string{e.Constraint}
+		}
+	}
+	fields := make([]string, 0, len(columns))
+	for _, column := range columns {
+		f := sch.LookUpField(column)
+		if f == nil {
+			continue
+		}
+		if name := model.NewJson(f.StructField).Name; name != "" {
+			fields = append(fields, name)
+		}
+	}
+	e.Fields = fields
+}
+
+// NewDBError 将数据库错误转换为 response.Error，唯一键冲突为409，外键与检查约束为422，死锁等可重试错误为503
+//
+//	无法识别的错误返回nil
+func NewDBError(result *gorm.DB) *response.Error {
+	dbErr := ClassifyDBError(result.Error)
+	if dbErr == nil {
+		return nil
+	}
+	var sch *schema.Schema
+	if result.Statement != nil {
+		sch = result.Statement.Schema
+	}
+	dbErr.jsonFields(sch)
+	var err *response.Error
+	switch dbErr.Type {
+	case DBErrorDuplicate:
+		msg := "duplicate value"
+		if len(dbErr.Fields) > 0 {
+			msg = fmt.Sprintf("duplicate value for %s", strings.Join(dbErr.Fields, ", "))
+		}
+		err = response.CodeConflict.New(msg)
+	case DBErrorForeignKey:
+		msg := "related data not exists"
+		if len(dbErr.Fields) > 0 {
+			msg = fmt.Sprintf("related data not exists for %s", strings.Join(dbErr.Fields, ", "))
+		}
+		err = response.CodeUnprocessable.New(msg)
+	case DBErrorCheck:
+		err = response.CodeUnprocessable.New("check constraint failed")
+	default:
+		err = response.CodeUnavailable.New("database busy, please retry")
+	}
+	err.Data = dbErr
+	return err
+}
@@ -0,0 +1,98 @@
+package restful
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// 与 go-sql-driver/mysql、pgconn 的错误结构一致
+type mysqlError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *mysqlError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
+
+type pgError struct {
+	Code           string
+	Detail         string
+	ConstraintName string
+}
+
+func (e *pgError) Error() string { return "ERROR: (SQLSTATE " + e.Code + ")" }
+
+type DBErrorUser struct {
+	ID     int64  `gorm:"column:id;primaryKey" json:"id"`
+	Email  string `gorm:"column:email;uniqueIndex:idx_email" json:"email"`
+	Tenant int64  `gorm:"column:tenant_id" json:"tenant"`
+}
+
+func TestClassifyDBError(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected *DBError
+	}{
+		{&mysqlError{1062, "Duplicate entry 'a' for key 'users.idx_email'"}, &DBError{Type: DBErrorDuplicate, Constraint: "idx_email"}},
+		{fmt.Errorf("wrap: %w", &mysqlError{1452, "Cannot add or update a child row: a foreign key constraint fails (`db`.`users`, CONSTRAINT `fk_tenant` FOREIGN KEY (`tenant_id`) REFERENCES `tenants` (`id`))"}),
+			&DBError{Type: DBErrorForeignKey, Constraint: "fk_tenant", Fields: []string{"tenant_id"}}},
+		{&mysqlError{3819, "Check constraint 'chk_age' is violated."}, &DBError{Type: DBErrorCheck, Constraint: "chk_age"}},
+		{&mysqlError{1213, "Deadlock found"}, &DBError{Type: DBErrorRetryable, Retryable: true}},
+		{&pgError{Code: "23505", Detail: "Key (email)=(a) already exists.", ConstraintName: "idx_email"},
+			&DBError{Type: DBErrorDuplicate, Constraint: "idx_email", Fields: []string{"email"}}},
+		{&pgError{Code: "40P01"}, &DBError{Type: DBErrorRetryable, Retryable: true}},
+		{errors.New("UNIQUE constraint failed: users.tenant_id, users.email"), &DBError{Type: DBErrorDuplicate, Fields: []string{"tenant_id", "email"}}},
+		{errors.New("CHECK constraint failed: chk_age"), &DBError{Type: DBErrorCheck, Constraint: "chk_age"}},
+		{errors.New("database is locked"), &DBError{Type: DBErrorRetryable, Retryable: true}},
+		{gorm.ErrForeignKeyViolated, &DBError{Type: DBErrorForeignKey}},
+		{errors.New("syntax error"), nil},
+		{&mysqlError{1064, "syntax error"}, nil},
+	}
+	for i, c := range cases {
+		if got := ClassifyDBError(c.err); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("case %d ClassifyDBError got=%+v, expected=%+v", i, got, c.expected)
+		}
+	}
+}
+
+func TestNewDBError(t *testing.T) {
+	sch, err := schema.Parse(&DBErrorUser{}, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &gorm.DB{Statement: &gorm.Statement{Schema: sch}}
+	cases := []struct {
+		err    error
+		status int
+		fields []string
+	}{
+		{&mysqlError{1062, "Duplicate entry 'a' for key 'users.idx_email'"}, 409, []string{"email"}},
+		{&mysqlError{1062, "Duplicate entry '1' for key 'PRIMARY'"}, 409, []string{"id"}},
+		{errors.New("UNIQUE constraint failed: users.tenant_id, users.email"), 409, []string{"tenant", "email"}},
+		{&mysqlError{1452, "a foreign key constraint fails (`db`.`users`, CONSTRAINT `fk` FOREIGN KEY (`tenant_id`) REFERENCES `tenants` (`id`))"}, 422, []string{"tenant"}},
+		{&pgError{Code: "23514", ConstraintName: "chk"}, 422, []string{}},
+		{&pgError{Code: "40001"}, 503, []string{}},
+	}
+	for i, c := range cases {
+		result.Error = c.err
+		e := NewDBError(result)
+		if e == nil || e.Status != c.status || !reflect.DeepEqual(e.Data.(*DBError).Fields, c.fields) {
+			t.Errorf("case %d NewDBError got=%+v", i, e)
+		}
+	}
+	result.Error = errors.New("syntax error")
+	if NewDBError(result) != nil {
+		t.Errorf("NewDBError unknown error should be nil")
+	}
+	// 无法识别的错误为500，不返回原始错误信息
+	e := NewDBResultError(result)
+	if e.Status != 500 || e.Code != "internal_error" || strings.Contains(e.Msg, "syntax") || !errors.Is(e, result.Error) {
+		t.Errorf("NewDBResultError unknown error got=%+v", e)
+	}
+}
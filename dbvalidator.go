@@ -0,0 +1,211 @@
+package restful
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lookupearth/restful/model"
+)
+
+// 查询数据库的校验 tag，New 中自动注册
+//
+//	unique                    字段值在表中唯一
+//	unique_together=field2    与其他字段（json字段或结构体字段名，多个以空格分隔）的组合唯一
+//	exists=table.column       值在指定表的列中存在，如 exists=categories.id
+//	通过当前请求的 Resource 查询，PUT/PATCH 时排除当前数据，非 Resource 请求中跳过
+const (
+	TagUnique         = "unique"
+	TagUniqueTogether = "unique_together"
+	TagExists         = "exists"
+)
+
+// RegisterDBValidations 注册 unique、unique_together、exists 校验
+//
+//	空指针同样调用校验函数，空值不校验，是否必填由 required 控制
+func (v *Validator) RegisterDBValidations() {
+	_ = v.Validator.RegisterValidationCtx(TagUnique, validateUnique, true)
+	_ = v.Validator.RegisterValidationCtx(TagUniqueTogether, validateUnique, true)
+	_ = v.Validator.RegisterValidationCtx(TagExists, validateExists, true)
+}
+
+// validateResource 获取当前请求的 Resource，非 Resource 请求返回nil
+func validateResource(ctx context.Context) (*gin.Context, IResource) {
+	c, ok := ctx.(*gin.Context)
+	if !ok {
+		return nil, nil
+	}
+	resource := ResourceFromContext(c)
+	if resource == nil {
+		return nil, nil
+	}
+	return c, resource
+}
+
+// fieldName 获取校验字段在 model 中的字段名，嵌入结构体中存在同名字段时按字段地址区分
+func fieldName(m *model.Model, fl validator.FieldLevel) (string, bool) {
+	candidates := make([]string, 0)
+	for _, name := range m.Names {
+		ns := m.Name2Field[name].Namespace
+		if ns == fl.StructFieldName() || strings.HasSuffix(ns, "."+fl.StructFieldName()) {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+	top := reflect.Indirect(fl.Top())
+	parent := reflect.Indirect(fl.Parent())
+	for _, name := range candidates {
+		index := m.Name2Field[name].Index
+		fv := m.FieldValue(top, name)
+		pv := parent.Field(index[len(index)-1])
+		if fv.CanAddr() && pv.CanAddr() && fv.UnsafeAddr() == pv.UnsafeAddr() {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// fieldValue 获取字段值，空指针返回nil
+func fieldValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// submitted 字段是否在请求中传入，仅部分校验（见 Serializer.Validate）时可能未传入
+func submitted(c *gin.Context, m *model.Model, name string) bool {
+	val, has := c.Get(ctxPartialData)
+	if !has {
+		return true
+	}
+	rawData, _ := val.(map[string]interface{})
+	if rawData == nil {
+		return true
+	}
+	_, ok := rawData[m.Name2Json[name]]
+	return ok
+}
+
+func isUpdate(c *gin.Context) bool {
+	return c.Request != nil && (c.Request.Method == http.MethodPut || c.Request.Method == http.MethodPatch)
+}
+
+func validateUnique(ctx context.Context, fl validator.FieldLevel) bool {
+	c, resource := validateResource(ctx)
+	if resource == nil {
+		return true
+	}
+	m := resource.GetModel()
+	name, ok := fieldName(m, fl)
+	if !ok || m.Name2Column[name] == "" {
+		return false
+	}
+	value := fieldValue(fl.Field())
+	// 与 exists 一致，空值不校验，NULL 不参与唯一约束
+	if value == nil {
+		return true
+	}
+	columns := []string{m.Name2Column[name]}
+	values := []interface{}{value}
+	if fl.GetTag() == TagUniqueTogether {
+		top := reflect.Indirect(fl.Top())
+		for _, param := range strings.Fields(fl.Param()) {
+			other, ok := m.Json2Name[param]
+			if !ok {
+				other = param
+			}
+			column, ok := m.Name2Column[other]
+			if !ok {
+				return false
+			}
+			value := fieldValue(m.FieldValue(top, other))
+			// 部分更新时未传入的字段使用当前数据的值，传入的零值照常使用
+			if !submitted(c, m, other) {
+				current := make(map[string]interface{})
+				result := whereLookup(validateQuery(c, resource), c, resource).Select(column).Limit(1).Find(&current)
+				CheckDBResult(result)
+				value = current[column]
+			}
+			columns = append(columns, column)
+			values = append(values, value)
+		}
+	}
+	query := validateQuery(c, resource)
+	for i, column := range columns {
+		query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: values[i]})
+	}
+	if isUpdate(c) {
+		query = excludeCurrent(query, c, resource)
+	}
+	var count int64
+	CheckDBResult(query.Count(&count))
+	return count == 0
+}
+
+// validateQuery 获取校验使用的查询句柄，在写事务内时使用同一事务，避免读取不到未提交的数据或等待连接
+func validateQuery(c *gin.Context, resource IResource) *gorm.DB {
+	if tx := TxFromContext(c); tx != nil {
+		return tx.Session(&gorm.Session{NewDB: true}).Model(resource.GetModel().New())
+	}
+	return resource.QueryWithContext(c)
+}
+
+// lookupCondition 详情路由对应的当前数据的查询条件
+func lookupCondition(c *gin.Context, resource IResource) clause.Expression {
+	lookup := ResourceLookup(resource, c)
+	columns := make([]string, 0, len(lookup))
+	for column := range lookup {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	exprs := make([]clause.Expression, 0, len(columns))
+	for _, column := range columns {
+		exprs = append(exprs, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Value: lookup[column]})
+	}
+	return clause.And(exprs...)
+}
+
+func whereLookup(query *gorm.DB, c *gin.Context, resource IResource) *gorm.DB {
+	return query.Where(lookupCondition(c, resource))
+}
+
+// excludeCurrent 排除详情路由对应的当前数据
+func excludeCurrent(query *gorm.DB, c *gin.Context, resource IResource) *gorm.DB {
+	return query.Where(clause.Not(lookupCondition(c, resource)))
+}
+
+func validateExists(ctx context.Context, fl validator.FieldLevel) bool {
+	c, resource := validateResource(ctx)
+	if resource == nil {
+		return true
+	}
+	cols := strings.SplitN(fl.Param(), ".", 2)
+	if len(cols) != 2 {
+		panic("exists should be like exists=table.column")
+	}
+	value := fieldValue(fl.Field())
+	if value == nil {
+		return true
+	}
+	var count int64
+	db := resource.GetDB().WithContext(DBContext(c))
+	if tx := TxFromContext(c); tx != nil {
+		db = tx.Session(&gorm.Session{NewDB: true})
+	}
+	result := db.Table(cols[0]).Where(clause.Eq{Column: clause.Column{Name: cols[1]}, Value: value}).Count(&count)
+	CheckDBResult(result)
+	return count > 0
+}
@@ -0,0 +1,141 @@
+package restful
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UniqueUser struct {
+	ID       int64  `gorm:"column:id;primaryKey" json:"id"`
+	Email    string `gorm:"column:email" json:"email" validate:"unique"`
+	Name     string `gorm:"column:name" json:"name" validate:"unique_together=tenant"`
+	Tenant   int64  `gorm:"column:tenant_id" json:"tenant"`
+	Category int64  `gorm:"column:category_id" json:"category" validate:"omitempty,exists=categories.id"`
+}
+
+func TestDBValidations(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(db)
+	r := New()
+	resource := NewResourceWithDB(db, &UniqueUser{})
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("PUT", "/users/5", nil)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+	ContextWithResource(c, resource)
+
+	// DryRun 时 count 为0，unique 通过，exists 失败
+	err := r.Validator.Validate(c, &UniqueUser{Email: "a@b.c", Name: "n", Tenant: 2, Category: 3})
+	if err == nil || !strings.Contains(err.Error(), "exists") {
+		t.Errorf("Validate exists should fail, error=%v", err)
+	}
+	expected := []string{
+		"SELECT count(*) FROM `unique_users` WHERE `unique_users`.`email` = 'a@b.c' AND `unique_users`.`id` <> 5",
+		"SELECT count(*) FROM `unique_users` WHERE `unique_users`.`name` = 'n' AND `unique_users`.`tenant_id` = 2 AND `unique_users`.`id` <> 5",
+		"SELECT count(*) FROM `categories` WHERE `id` = 3",
+	}
+	if strings.Join(*sqls, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Validate sql fail, got=%v", *sqls)
+	}
+
+	// POST 不排除当前数据，非 Resource 请求跳过
+	*sqls = (*sqls)[:0]
+	c.Request = httptest.NewRequest("POST", "/users", nil)
+	if err := r.Validator.Validate(c, &UniqueUser{Email: "a@b.c", Name: "n"}); err != nil {
+		t.Errorf("Validate fail, error=%v", err)
+	}
+	if len(*sqls) != 2 || strings.Contains((*sqls)[0], "<>") {
+		t.Errorf("Validate post sql fail, got=%v", *sqls)
+	}
+	c2, _ := gin.CreateTestContext(httptest.NewRecorder())
+	if err := r.Validator.Validate(c2, &UniqueUser{Category: 3}); err != nil {
+		t.Errorf("Validate without resource fail, error=%v", err)
+	}
+}
+
+type NullableUser struct {
+	ID    int64   `gorm:"column:id;primaryKey" json:"id"`
+	Phone *string `gorm:"column:phone" json:"phone" validate:"unique"`
+}
+
+func TestUniqueNull(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(db)
+	r := New()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/users", nil)
+	ContextWithResource(c, NewResourceWithDB(db, &NullableUser{}))
+
+	// 空值不参与唯一校验
+	if err := r.Validator.Validate(c, &NullableUser{}); err != nil || len(*sqls) != 0 {
+		t.Errorf("Validate null unique fail, error=%v sql=%v", err, *sqls)
+	}
+	phone := "123"
+	if err := r.Validator.Validate(c, &NullableUser{Phone: &phone}); err != nil || len(*sqls) != 1 {
+		t.Errorf("Validate unique fail, error=%v sql=%v", err, *sqls)
+	}
+}
+
+func TestUniqueTogetherPartial(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(db)
+	r := New()
+	resource := NewResourceWithDB(db, &UniqueUser{})
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("PATCH", "/users/5", nil)
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+	ContextWithResource(c, resource)
+
+	cases := []struct {
+		body     string
+		expected []string
+	}{
+		{
+			// 传入的零值照常使用
+			`{"name":"n","tenant":0}`,
+			[]string{
+				"SELECT count(*) FROM `unique_users` WHERE `unique_users`.`name` = 'n' AND `unique_users`.`tenant_id` = 0 AND `unique_users`.`id` <> 5",
+			},
+		},
+		{
+			// 未传入的字段读取当前数据，DryRun 时为空
+			`{"name":"n"}`,
+			[]string{
+				"SELECT `tenant_id` FROM `unique_users` WHERE `unique_users`.`id` = 5 LIMIT 1",
+				"SELECT count(*) FROM `unique_users` WHERE `unique_users`.`name` = 'n' AND `unique_users`.`tenant_id` IS NULL AND `unique_users`.`id` <> 5",
+			},
+		},
+	}
+	for _, cs := range cases {
+		*sqls = (*sqls)[:0]
+		s := NewSerializer(resource.Model, r.Validator, true)
+		if err := s.Parse(c, []byte(cs.body)); err != nil {
+			t.Fatalf("Parse fail, error=%v", err)
+		}
+		if err := s.Validate(c); err != nil {
+			t.Errorf("Validate fail, body=%s error=%v", cs.body, err)
+		}
+		if strings.Join(*sqls, "\n") != strings.Join(cs.expected, "\n") {
+			t.Errorf("Validate partial sql fail, body=%s got=%v", cs.body, *sqls)
+		}
+	}
+	// 校验结束后不再按部分校验处理
+	if !submitted(c, resource.Model, "Tenant") {
+		t.Errorf("partial data should be cleared after Validate")
+	}
+}
+
+func TestContextWithTx(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	tx := dryRunDB(t)
+	clearTx := ContextWithTx(c, tx)
+	if TxFromContext(c) != tx {
+		t.Errorf("TxFromContext fail")
+	}
+	clearTx()
+	if TxFromContext(c) != nil {
+		t.Errorf("TxFromContext should be nil after clear")
+	}
+}
@@ -0,0 +1,89 @@
+package restful
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+
+	"github.com/lookupearth/restful/response"
+)
+
+func TestErrorCode(t *testing.T) {
+	app := gin.New()
+	root := New()
+	root.RegisterResource("/demo", newVersionDemo())
+	root.Mount(app.Group("/api"))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/demo/echo", nil))
+	var res response.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusMethodNotAllowed || res.Code != "method_not_allowed" || w.Header().Get("Allow") != "POST,OPTIONS" {
+		t.Errorf("method not allowed fail, code=%d body=%s", w.Code, w.Body.String())
+	}
+
+	// 业务状态码与 http 状态码分离
+	w = httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	code := response.RegisterErrorCode("demo_quota_exceeded", http.StatusTooManyRequests, "quota exceeded")
+	e := code.New("")
+	e.Status, e.HTTPStatus = 10001, code.HTTPStatus
+	e.WithDetails(map[string]int{"limit": 10}).Response(c)
+	if w.Code != http.StatusTooManyRequests || w.Body.String() != `{"status":10001,"msg":"quota exceeded","code":"demo_quota_exceeded","details":{"limit":10}}` {
+		t.Errorf("custom error code fail, code=%d body=%s", w.Code, w.Body.String())
+	}
+	if response.GetErrorCode("demo_quota_exceeded") != code || response.NewErrorFromMsg(404, "x").Code != "not_found" {
+		t.Errorf("error code registry fail")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("RegisterErrorCode duplicate should panic")
+			}
+		}()
+		response.RegisterErrorCode("not_found", 404, "")
+	}()
+
+	valid := &Validator{Validator: validator.New()}
+	err := valid.Validate(context.Background(), &VaTest{})
+	if err == nil || err.Code != "validation_failed" || len(err.Details.([]*FieldError)) != 2 {
+		t.Errorf("validation failed error fail, got=%+v", err)
+	}
+}
+
+func TestHookError(t *testing.T) {
+	wrapped := fmt.Errorf("post before: %w", Forbidden("denied"))
+	e := response.NewError(500, wrapped)
+	if e.Status != http.StatusForbidden || e.Code != "forbidden" || e.Msg != "post before: denied" {
+		t.Errorf("NewError wrapped fail, got=%+v", e)
+	}
+	if !errors.Is(e, response.CodeForbidden) || !errors.Is(wrapped, response.CodeForbidden) || errors.Is(e, response.CodeNotFound) {
+		t.Errorf("errors.Is error code fail")
+	}
+	if e := response.NewError(500, gorm.ErrRecordNotFound); e.Status != 500 || !errors.Is(e, gorm.ErrRecordNotFound) {
+		t.Errorf("NewError unwrap fail, got=%+v", e)
+	}
+
+	demo := &DemoResource{Resource: NewResource(&DemoTable{})}
+	demo.RegisterMethod(ListMethod, HTTPMethodPost, "conflict", func(c *gin.Context) Response {
+		panic(fmt.Errorf("create: %w", Conflict("duplicate name")))
+	})
+	app := gin.New()
+	root := New()
+	root.RegisterResource("/demo", demo)
+	root.Mount(app.Group("/api"))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/demo/conflict", strings.NewReader(`{}`)))
+	if w.Code != http.StatusConflict || w.Body.String() != `{"status":409,"msg":"create: duplicate name","code":"conflict"}` {
+		t.Errorf("recover wrapped error fail, code=%d body=%s", w.Code, w.Body.String())
+	}
+}
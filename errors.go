@@ -0,0 +1,42 @@
+package restful
+
+import (
+	"github.com/lookupearth/restful/response"
+)
+
+// 常用的带状态错误，可在 *Before/*After 等 hook 中直接返回或通过 fmt.Errorf("...: %w", err) 包装后返回，
+// mixins 通过 errors.As 识别并返回对应的状态码，未识别的错误为500
+//
+//	if !allowed {
+//		return restful.Forbidden("permission denied")
+//	}
+
+// BadRequest 400
+func BadRequest(msg string) *response.Error {
+	return response.CodeBadRequest.New(msg)
+}
+
+// Unauthorized 401
+func Unauthorized(msg string) *response.Error {
+	return response.CodeUnauthorized.New(msg)
+}
+
+// Forbidden 403
+func Forbidden(msg string) *response.Error {
+	return response.CodeForbidden.New(msg)
+}
+
+// NotFound 404
+func NotFound(msg string) *response.Error {
+	return response.CodeNotFound.New(msg)
+}
+
+// Conflict 409
+func Conflict(msg string) *response.Error {
+	return response.CodeConflict.New(msg)
+}
+
+// Unprocessable 422
+func Unprocessable(msg string) *response.Error {
+	return response.CodeUnprocessable.New(msg)
+}
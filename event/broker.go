@@ -0,0 +1,82 @@
+package event
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Notifier 事务提交后接收事件，用于进程内通知等不需要事务保证的场景
+type Notifier interface {
+	Notify(*Event)
+}
+
+// Broker 进程内事件广播，按资源订阅，订阅者处理不及时会丢弃事件
+//
+//	Broker 同时实现 Sink 与 Notifier，Emit 不做处理，事务提交后由 Notify 广播
+type Broker struct {
+	// Buffer 每个订阅者的缓冲大小
+	Buffer int
+
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+}
+
+// Subscriber 订阅者，从 C 读取事件
+type Subscriber struct {
+	Resource string
+	C        chan *Event
+
+	broker *Broker
+	once   sync.Once
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		Buffer:      64,
+		subscribers: make(map[*Subscriber]struct{}),
+	}
+}
+
+// Subscribe 订阅资源事件，resource 为空表示全部资源
+func (b *Broker) Subscribe(resource string) *Subscriber {
+	s := &Subscriber{
+		Resource: resource,
+		C:        make(chan *Event, b.Buffer),
+		broker:   b,
+	}
+	b.mu.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Close 取消订阅并关闭 C
+func (s *Subscriber) Close() {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		delete(s.broker.subscribers, s)
+		s.broker.mu.Unlock()
+		close(s.C)
+	})
+}
+
+// Emit 实现 Sink，事务内不做处理
+func (b *Broker) Emit(tx *gorm.DB, e *Event) error {
+	return nil
+}
+
+// Notify 实现 Notifier，非阻塞广播
+func (b *Broker) Notify(e *Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subscribers {
+		if len(s.Resource) > 0 && s.Resource != e.Resource {
+			continue
+		}
+		select {
+		case s.C <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package event
+
+import (
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB 不连接数据库的 mysql DryRun 实例，用于校验生成的SQL
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "root:root@tcp(127.0.0.1:3306)/demo",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("gorm.Open fail, error=%v", err)
+	}
+	return db
+}
+
+// captureSQL 记录 db 执行 create、query 生成的SQL
+func captureSQL(db *gorm.DB) *[]string {
+	sqls := make([]string, 0)
+	capture := func(tx *gorm.DB) {
+		sqls = append(sqls, tx.Statement.SQL.String())
+	}
+	_ = db.Callback().Create().After("gorm:create").Register("test:capture", capture)
+	_ = db.Callback().Query().After("gorm:query").Register("test:capture", capture)
+	return &sqls
+}
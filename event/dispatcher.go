@@ -0,0 +1,157 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BackoffFunc 根据已尝试次数计算下次重试的等待时间
+type BackoffFunc func(attempts int) time.Duration
+
+// ExponentialBackoff 指数退避，base * 2^(attempts-1)，不超过 max
+func ExponentialBackoff(base, max time.Duration) BackoffFunc {
+	return func(attempts int) time.Duration {
+		if attempts < 1 {
+			attempts = 1
+		}
+		d := base
+		for i := 1; i < attempts; i++ {
+			d *= 2
+			if d >= max || d <= 0 {
+				return max
+			}
+		}
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// Dispatcher 轮询 Outbox，将待投递事件交给 Publisher
+//
+//	每批记录在短事务内领取后再投递，同一事件可能重复投递（至少一次），Publisher 需按 Event.ID 去重
+type Dispatcher struct {
+	Outbox      *Outbox
+	Publisher   Publisher
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	Backoff     BackoffFunc
+	// Lease 领取后的租约时长，需大于一批事件的投递耗时
+	Lease time.Duration
+	// OnError 投递/查询失败时回调，可用于记录日志
+	OnError func(error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewDispatcher(outbox *Outbox, publisher Publisher) *Dispatcher {
+	return &Dispatcher{
+		Outbox:      outbox,
+		Publisher:   publisher,
+		Interval:    time.Second,
+		BatchSize:   100,
+		MaxAttempts: 10,
+		Backoff:     ExponentialBackoff(time.Second, 10*time.Minute),
+		Lease:       5 * time.Minute,
+	}
+}
+
+// Start 启动后台投递 goroutine，重复调用无效
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		return
+	}
+	ctx, d.cancel = context.WithCancel(ctx)
+	d.done = make(chan struct{})
+	go d.run(ctx, d.done)
+}
+
+// Stop 停止投递并等待当前批次结束
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.done = nil, nil
+	d.mu.Unlock()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (d *Dispatcher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		// 一批处理满时说明可能还有积压，立即继续
+		for {
+			n, err := d.Drain(ctx)
+			if err != nil {
+				d.error(err)
+			}
+			if err != nil || n < d.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain 处理一批到期事件，返回处理的记录数
+func (d *Dispatcher) Drain(ctx context.Context) (int, error) {
+	records, err := d.Outbox.Claim(ctx, d.BatchSize, d.Lease)
+	if err != nil {
+		return 0, err
+	}
+	// 停止时仍记录已投递事件的结果
+	db := d.Outbox.DB.WithContext(context.WithoutCancel(ctx))
+	for _, record := range records {
+		// 未投递的记录在租约到期后重新领取
+		if ctx.Err() != nil {
+			break
+		}
+		if err := d.dispatch(ctx, db, record); err != nil {
+			d.error(err)
+		}
+	}
+	return len(records), nil
+}
+
+// dispatch 投递单条事件并记录结果，每条记录单独更新，失败不影响同批其他记录
+func (d *Dispatcher) dispatch(ctx context.Context, db *gorm.DB, record *Record) error {
+	e, err := record.Event()
+	if err == nil {
+		err = d.Publisher.Publish(ctx, e)
+	}
+	if err == nil {
+		return d.Outbox.MarkDone(db, record)
+	}
+	d.error(err)
+	attempts := int(record.Attempts) + 1
+	dead := d.MaxAttempts > 0 && attempts >= d.MaxAttempts
+	next := time.Now()
+	if d.Backoff != nil {
+		next = next.Add(d.Backoff(attempts))
+	}
+	return d.Outbox.MarkFailed(db, record, err, next, dead)
+}
+
+func (d *Dispatcher) error(err error) {
+	if d.OnError != nil && !errors.Is(err, context.Canceled) {
+		d.OnError(err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package event 资源写操作事件，mixins 在写事务内发出事件，由 Sink 负责落地/分发
+package event
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Action 事件动作
+type Action string
+
+const (
+	ActionCreate Action = "created"
+	ActionUpdate Action = "updated"
+	ActionDelete Action = "deleted"
+)
+
+// Event 资源变更事件
+type Event struct {
+	ID         string                 `json:"id"`
+	Resource   string                 `json:"resource"`
+	Action     Action                 `json:"action"`
+	PrimaryKey interface{}            `json:"pk"`
+	Fields     []string               `json:"fields,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	Time       time.Time              `json:"time"`
+}
+
+// NewEvent 创建事件，ID 随机生成
+func NewEvent(resource string, action Action, pk interface{}) *Event {
+	return &Event{
+		ID:         NewID(),
+		Resource:   resource,
+		Action:     action,
+		PrimaryKey: pk,
+		Time:       time.Now(),
+	}
+}
+
+// Topic 事件主题，形如 order.created
+func (e *Event) Topic() string {
+	return e.Resource + "." + string(e.Action)
+}
+
+// Sink 事件接收方，Emit 在写事务内调用，返回错误时写操作会回滚
+//
+//	tx 为写事务上的新会话，不带写操作的 Model 与查询条件，可直接用于读写其他表
+type Sink interface {
+	Emit(*gorm.DB, *Event) error
+}
+
+// SinkFunc 函数形式的 Sink
+type SinkFunc func(*gorm.DB, *Event) error
+
+func (f SinkFunc) Emit(tx *gorm.DB, e *Event) error {
+	return f(tx, e)
+}
+
+// NewID 生成32位随机hex字符串
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,122 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	e := NewEvent("order", ActionCreate, int64(1))
+	if e.Topic() != "order.created" {
+		t.Errorf("Event.Topic fail, got=%s", e.Topic())
+	}
+	if len(e.ID) != 32 || e.ID == NewEvent("order", ActionCreate, 1).ID {
+		t.Errorf("Event.ID fail, got=%s", e.ID)
+	}
+	b, _ := json.Marshal(e)
+	record := &Record{Payload: b}
+	e2, err := record.Event()
+	if err != nil || e2.ID != e.ID || e2.Action != ActionCreate {
+		t.Errorf("Record.Event fail, error=%v", err)
+	}
+}
+
+func TestOutboxEmit(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(db)
+	outbox := NewOutbox(db)
+	tx := db.Table("order").Where("id = ?", 1)
+	if err := outbox.Emit(tx, NewEvent("order", ActionUpdate, 1)); err != nil {
+		t.Errorf("Outbox.Emit fail, error=%v", err)
+	}
+	if len(*sqls) != 1 || !strings.HasPrefix((*sqls)[0], "INSERT INTO `restful_outbox` (`event_id`,`topic`,`payload`,`status`") {
+		t.Errorf("Outbox.Emit sql fail, got=%v", *sqls)
+	}
+}
+
+func TestOutboxPending(t *testing.T) {
+	db := dryRunDB(t)
+	sqls := captureSQL(db)
+	outbox := NewOutbox(db)
+	if _, err := outbox.Pending(db, 10); err != nil {
+		t.Errorf("Outbox.Pending fail, error=%v", err)
+	}
+	expect := "SELECT * FROM `restful_outbox` WHERE status IN (?,?) AND next_retry_time <= ? ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED"
+	if len(*sqls) != 1 || (*sqls)[0] != expect {
+		t.Errorf("Outbox.Pending sql fail, got=%v", *sqls)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(time.Second, 10*time.Second)
+	cases := map[int]time.Duration{
+		0:   time.Second,
+		1:   time.Second,
+		2:   2 * time.Second,
+		3:   4 * time.Second,
+		4:   8 * time.Second,
+		5:   10 * time.Second,
+		100: 10 * time.Second,
+	}
+	for attempts, want := range cases {
+		if got := backoff(attempts); got != want {
+			t.Errorf("ExponentialBackoff(%d) fail, expect=%v got=%v", attempts, want, got)
+		}
+	}
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	p := NewMemoryPublisher()
+	received := 0
+	p.Subscribe(func(e *Event) {
+		received++
+	})
+	_ = p.Publish(context.Background(), NewEvent("order", ActionDelete, 1))
+	if received != 1 || len(p.Events()) != 1 {
+		t.Errorf("MemoryPublisher fail, received=%d events=%d", received, len(p.Events()))
+	}
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	status := http.StatusOK
+	var topic string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		topic = r.Header.Get("X-Event-Topic")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	p := NewWebhookPublisher(server.URL)
+	if err := p.Publish(context.Background(), NewEvent("order", ActionCreate, 1)); err != nil {
+		t.Errorf("WebhookPublisher.Publish fail, error=%v", err)
+	}
+	if topic != "order.created" {
+		t.Errorf("WebhookPublisher topic fail, got=%s", topic)
+	}
+	status = http.StatusInternalServerError
+	if err := p.Publish(context.Background(), NewEvent("order", ActionCreate, 1)); err == nil {
+		t.Errorf("WebhookPublisher.Publish expect error")
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+	order := b.Subscribe("order")
+	all := b.Subscribe("")
+	b.Notify(NewEvent("order", ActionCreate, 1))
+	b.Notify(NewEvent("user", ActionCreate, 1))
+	if len(order.C) != 1 || len(all.C) != 2 {
+		t.Errorf("Broker.Notify fail, order=%d all=%d", len(order.C), len(all.C))
+	}
+	order.Close()
+	order.Close()
+	b.Notify(NewEvent("order", ActionUpdate, 1))
+	if len(all.C) != 3 {
+		t.Errorf("Broker.Notify after Close fail, all=%d", len(all.C))
+	}
+}
@@ -0,0 +1,160 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const DefaultOutboxTable = "restful_outbox"
+
+// Outbox 记录状态
+const (
+	StatusPending int32 = 0
+	StatusDone    int32 = 1
+	StatusDead    int32 = 2
+	// StatusInFlight 已被 Dispatcher 领取，NextRetryTime 为租约到期时间，到期未记录结果时重新投递
+	StatusInFlight int32 = 3
+)
+
+// Record outbox 表结构
+type Record struct {
+	ID            int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	EventID       string    `gorm:"column:event_id;size:64;uniqueIndex"`
+	Topic         string    `gorm:"column:topic;size:255"`
+	Payload       []byte    `gorm:"column:payload"`
+	Status        int32     `gorm:"column:status;index:idx_outbox_status_retry,priority:1"`
+	Attempts      int32     `gorm:"column:attempts"`
+	NextRetryTime time.Time `gorm:"column:next_retry_time;index:idx_outbox_status_retry,priority:2"`
+	LastError     string    `gorm:"column:last_error;size:1024"`
+	CreateTime    time.Time `gorm:"column:create_time"`
+	UpdateTime    time.Time `gorm:"column:update_time"`
+}
+
+// Event 解析 Payload
+func (r *Record) Event() (*Event, error) {
+	e := &Event{}
+	if err := json.Unmarshal(r.Payload, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Outbox 事务性 outbox，写操作在同一事务内落表，由 Dispatcher 异步投递
+type Outbox struct {
+	DB    *gorm.DB
+	Table string
+}
+
+func NewOutbox(db *gorm.DB) *Outbox {
+	return &Outbox{
+		DB:    db,
+		Table: DefaultOutboxTable,
+	}
+}
+
+// AutoMigrate 创建 outbox 表
+func (o *Outbox) AutoMigrate() error {
+	return o.DB.Table(o.Table).AutoMigrate(&Record{})
+}
+
+// Emit 实现 Sink，tx 为写操作所在事务
+func (o *Outbox) Emit(tx *gorm.DB, e *Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	record := &Record{
+		EventID:       e.ID,
+		Topic:         e.Topic(),
+		Payload:       payload,
+		Status:        StatusPending,
+		NextRetryTime: now,
+		CreateTime:    now,
+		UpdateTime:    now,
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).Table(o.Table).Create(record).Error
+}
+
+// Pending 在事务 tx 内锁定到期的待投递记录，包括租约已到期的投递中记录
+func (o *Outbox) Pending(tx *gorm.DB, limit int) ([]*Record, error) {
+	records := make([]*Record, 0)
+	query := tx.Table(o.Table).
+		Where("status IN ? AND next_retry_time <= ?", []int32{StatusPending, StatusInFlight}, time.Now()).
+		Order("id").
+		Limit(limit)
+	if tx.Dialector.Name() != "sqlite" {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+	}
+	result := query.Find(&records)
+	return records, result.Error
+}
+
+// Claim 在短事务内领取一批到期记录并标记为投递中，事务提交后再投递，不在持有行锁时发起网络请求
+//
+//	lease 为租约时长，期间其他 Dispatcher 不会重复领取；投递后未记录结果（如进程退出）时，租约到期会重新投递
+func (o *Outbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]*Record, error) {
+	records := make([]*Record, 0)
+	err := o.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		records, err = o.Pending(tx, limit)
+		if err != nil || len(records) == 0 {
+			return err
+		}
+		ids := make([]int64, 0, len(records))
+		for _, record := range records {
+			ids = append(ids, record.ID)
+		}
+		now := time.Now()
+		return tx.Table(o.Table).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":          StatusInFlight,
+			"next_retry_time": now.Add(lease),
+			"update_time":     now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// MarkDone 标记投递成功
+func (o *Outbox) MarkDone(db *gorm.DB, record *Record) error {
+	return db.Table(o.Table).Where("id = ?", record.ID).Updates(map[string]interface{}{
+		"status":      StatusDone,
+		"attempts":    record.Attempts + 1,
+		"last_error":  "",
+		"update_time": time.Now(),
+	}).Error
+}
+
+// MarkFailed 标记投递失败，dead=true 时不再重试
+func (o *Outbox) MarkFailed(db *gorm.DB, record *Record, err error, next time.Time, dead bool) error {
+	status := StatusPending
+	if dead {
+		status = StatusDead
+	}
+	msg := err.Error()
+	if len(msg) > 1024 {
+		msg = msg[:1024]
+	}
+	return db.Table(o.Table).Where("id = ?", record.ID).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        record.Attempts + 1,
+		"next_retry_time": next,
+		"last_error":      msg,
+		"update_time":     time.Now(),
+	}).Error
+}
+
+// Purge 清理 before 之前已投递的记录
+func (o *Outbox) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := o.DB.WithContext(ctx).Table(o.Table).
+		Where("status = ? AND update_time < ?", StatusDone, before).
+		Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
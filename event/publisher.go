@@ -0,0 +1,107 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Publisher 事件发布接口，由 Dispatcher 调用，返回错误时会按退避策略重试
+type Publisher interface {
+	Publish(context.Context, *Event) error
+}
+
+// PublisherFunc 函数形式的 Publisher
+type PublisherFunc func(context.Context, *Event) error
+
+func (f PublisherFunc) Publish(ctx context.Context, e *Event) error {
+	return f(ctx, e)
+}
+
+// MemoryPublisher 进程内发布，记录已发布事件并同步通知订阅者，便于测试/单机使用
+type MemoryPublisher struct {
+	mu       sync.RWMutex
+	events   []*Event
+	handlers []func(*Event)
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Subscribe 订阅事件
+func (p *MemoryPublisher) Subscribe(handler func(*Event)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers = append(p.handlers, handler)
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, e *Event) error {
+	p.mu.Lock()
+	p.events = append(p.events, e)
+	handlers := p.handlers
+	p.mu.Unlock()
+	for _, handler := range handlers {
+		handler(e)
+	}
+	return nil
+}
+
+// Events 已发布的事件
+func (p *MemoryPublisher) Events() []*Event {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	events := make([]*Event, len(p.events))
+	copy(events, p.events)
+	return events
+}
+
+// WebhookPublisher 以 POST json 的形式投递事件，非 2xx 视为失败
+type WebhookPublisher struct {
+	URL     string
+	Headers map[string]string
+	Client  *http.Client
+}
+
+func NewWebhookPublisher(url string) *WebhookPublisher {
+	return &WebhookPublisher{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, e *Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", e.ID)
+	req.Header.Set("X-Event-Topic", e.Topic())
+	for key, value := range p.Headers {
+		req.Header.Set(key, value)
+	}
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s response status %d", p.URL, resp.StatusCode)
+	}
+	return nil
+}
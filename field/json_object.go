@@ -10,8 +10,13 @@ type JSONObject map[string]interface{}
 
 // Scan 读取db
 func (j *JSONObject) Scan(value interface{}) error {
-	bytes, ok := value.([]byte)
-	if !ok {
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
 		return fmt.Errorf("failed to unmarshal JSONB value:%s", value)
 	}
 
@@ -23,6 +23,9 @@ func TestJson(t *testing.T) {
 	if err := jp.Scan([]byte(jsonStr)); err != nil {
 		t.Errorf("TestJson Scan fail, error=%v", err)
 	}
+	if err := (&JSON{}).Scan(jsonStr); err != nil {
+		t.Errorf("TestJson Scan string fail, error=%v", err)
+	}
 	dbValue, err := jp.Value()
 	if err != nil {
 		t.Errorf("TestJson Value fail, error=%v", err)
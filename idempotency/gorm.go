@@ -0,0 +1,107 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const DefaultTable = "restful_idempotency"
+
+// gormRecord 幂等记录表结构
+type gormRecord struct {
+	Key         string    `gorm:"column:idempotency_key;primaryKey;size:255"`
+	Fingerprint string    `gorm:"column:fingerprint;size:64"`
+	Done        bool      `gorm:"column:done"`
+	Body        []byte    `gorm:"column:body"`
+	HTTPStatus  int       `gorm:"column:http_status"`
+	ExpireTime  time.Time `gorm:"column:expire_time;index"`
+	CreateTime  time.Time `gorm:"column:create_time"`
+}
+
+// GormStore 基于数据库表的存储，多实例部署时使用
+type GormStore struct {
+	DB    *gorm.DB
+	Table string
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{
+		DB:    db,
+		Table: DefaultTable,
+	}
+}
+
+// AutoMigrate 创建幂等记录表
+func (s *GormStore) AutoMigrate() error {
+	return s.DB.Table(s.Table).AutoMigrate(&gormRecord{})
+}
+
+func (s *GormStore) Acquire(ctx context.Context, record *Record) (*Record, error) {
+	db := s.DB.WithContext(ctx).Table(s.Table).Session(&gorm.Session{})
+	row := &gormRecord{
+		Key:         record.Key,
+		Fingerprint: record.Fingerprint,
+		Done:        record.Done,
+		Body:        record.Body,
+		HTTPStatus:  record.HTTPStatus,
+		ExpireTime:  record.ExpireTime,
+		CreateTime:  time.Now(),
+	}
+	// 插入冲突时不做处理，通过 RowsAffected 判断是否占用成功
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(row)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil, nil
+	}
+	exists := &gormRecord{}
+	if err := db.Where("idempotency_key = ?", record.Key).First(exists).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 并发释放，重新占用
+			return s.Acquire(ctx, record)
+		}
+		return nil, err
+	}
+	if time.Now().After(exists.ExpireTime) {
+		// 已过期，删除后重新占用，带上过期条件避免并发时误删新记录
+		result := db.Where("idempotency_key = ? AND expire_time < ?", exists.Key, time.Now()).Delete(&gormRecord{})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		return s.Acquire(ctx, record)
+	}
+	return &Record{
+		Key:         exists.Key,
+		Fingerprint: exists.Fingerprint,
+		Done:        exists.Done,
+		Body:        exists.Body,
+		HTTPStatus:  exists.HTTPStatus,
+		ExpireTime:  exists.ExpireTime,
+	}, nil
+}
+
+func (s *GormStore) Complete(ctx context.Context, record *Record) error {
+	return s.DB.WithContext(ctx).Table(s.Table).
+		Where("idempotency_key = ?", record.Key).
+		Updates(map[string]interface{}{
+			"done":        record.Done,
+			"body":        record.Body,
+			"http_status": record.HTTPStatus,
+			"expire_time": record.ExpireTime,
+		}).Error
+}
+
+func (s *GormStore) Release(ctx context.Context, key string) error {
+	return s.DB.WithContext(ctx).Table(s.Table).Where("idempotency_key = ?", key).Delete(&gormRecord{}).Error
+}
+
+// Purge 清理已过期记录
+func (s *GormStore) Purge(ctx context.Context) (int64, error) {
+	result := s.DB.WithContext(ctx).Table(s.Table).Where("expire_time < ?", time.Now()).Delete(&gormRecord{})
+	return result.RowsAffected, result.Error
+}
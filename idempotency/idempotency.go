@@ -0,0 +1,145 @@
+// Package idempotency 基于 Idempotency-Key 请求头的幂等处理，以装饰器形式安装到写操作上
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/response"
+)
+
+const (
+	DefaultHeader  = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+// Idempotency 幂等装饰器
+//
+//	同一个 key 首次请求的结果会被记录，重试时直接返回记录的结果；
+//	请求处理中再次收到相同 key 返回409，相同 key 但请求内容不同返回422；
+//	5xx 错误不记录，客户端可以使用相同 key 重试。
+type Idempotency struct {
+	Store  Store
+	Header string
+	TTL    time.Duration
+	// Scope 可选，key 的作用域，如当前用户，避免不同用户的 key 冲突
+	Scope func(*gin.Context) string
+}
+
+func New(store Store) *Idempotency {
+	return &Idempotency{
+		Store:  store,
+		Header: DefaultHeader,
+		TTL:    24 * time.Hour,
+	}
+}
+
+// Decorator 实现 restful.HandlerDecorator，可用于 PostMethod 等 mixins 的 Decorators，也可用于自定义的批量写接口
+func (i *Idempotency) Decorator(handler restful.HandlerFunc) restful.HandlerFunc {
+	return func(c *gin.Context) restful.Response {
+		key := c.GetHeader(i.Header)
+		if len(key) == 0 {
+			return handler(c)
+		}
+		if i.Scope != nil {
+			key = i.Scope(c) + ":" + key
+		}
+		record := &Record{
+			Key:         key,
+			Fingerprint: fingerprint(c),
+			ExpireTime:  time.Now().Add(i.TTL),
+		}
+		exists, err := i.Store.Acquire(c, record)
+		if err != nil {
+			return response.NewError(500, err)
+		}
+		if exists != nil {
+			return i.replay(c, record, exists)
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				_ = i.Store.Release(c, key)
+			}
+		}()
+		res := handler(c)
+		body, httpStatus, ok := i.body(res)
+		if !ok {
+			return res
+		}
+		record.Done = true
+		record.Body = body
+		record.HTTPStatus = httpStatus
+		if err := i.Store.Complete(c, record); err != nil {
+			return res
+		}
+		completed = true
+		return res
+	}
+}
+
+func (i *Idempotency) replay(c *gin.Context, record *Record, exists *Record) restful.Response {
+	if exists.Fingerprint != record.Fingerprint {
+		return response.NewErrorFromMsg(422, i.Header+" has been used with a different request")
+	}
+	if !exists.Done {
+		return response.NewErrorFromMsg(409, "a request with the same "+i.Header+" is being processed")
+	}
+	res := &response.Response{}
+	if err := json.Unmarshal(exists.Body, res); err != nil {
+		return response.NewError(500, err)
+	}
+	res.LogID = ""
+	res.HTTPStatus = exists.HTTPStatus
+	c.Header(ReplayedHeader, "true")
+	return res
+}
+
+// body 需要记录的结果及指定的 http 状态码，5xx 错误与非标准返回值不记录
+func (i *Idempotency) body(res restful.Response) ([]byte, int, bool) {
+	var stored *response.Response
+	switch r := res.(type) {
+	case *response.Response:
+		stored = r
+	case *response.Error:
+		stored = &response.Response{
+			Status:     r.GetStatus(),
+			Msg:        r.Error(),
+			Data:       r.GetData(),
+			Code:       r.Code,
+			Details:    r.Details,
+			HTTPStatus: r.HTTPStatus,
+		}
+	default:
+		return nil, 0, false
+	}
+	if stored.HTTPStatus >= 500 || stored.Status >= 500 || (stored.Status != 0 && stored.Status < 400) {
+		return nil, 0, false
+	}
+	body, err := json.Marshal(stored)
+	if err != nil {
+		return nil, 0, false
+	}
+	return body, stored.HTTPStatus, true
+}
+
+// fingerprint 请求指纹，由 method、path、query 与 body 组成，query 按参数名排序
+func fingerprint(c *gin.Context) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method))
+	h.Write([]byte(" "))
+	h.Write([]byte(c.Request.URL.Path))
+	h.Write([]byte("?"))
+	h.Write([]byte(c.Request.URL.Query().Encode()))
+	h.Write([]byte("\n"))
+	if requestBody := restful.RequestBodyFromContext(c); requestBody != nil {
+		h.Write(requestBody.Get())
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,145 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/response"
+)
+
+type orderModel struct {
+	ID int64 `gorm:"column:id;primaryKey" json:"id"`
+}
+
+type orderResource struct {
+	*restful.Resource
+	idempotency *Idempotency
+	calls       int
+}
+
+func (r *orderResource) GetDecorators() []restful.HandlerDecorator {
+	return []restful.HandlerDecorator{r.idempotency.Decorator}
+}
+
+func (r *orderResource) create(c *gin.Context) restful.Response {
+	r.calls++
+	body := string(restful.RequestBodyFromContext(c).Get())
+	switch {
+	case strings.Contains(body, "fail"):
+		return response.NewErrorFromMsg(500, "fail")
+	case strings.Contains(body, "conflict"):
+		return response.CodeConflict.New("duplicate order").WithDetails([]string{"amount"})
+	case strings.Contains(body, "created"):
+		return &response.Response{Data: map[string]interface{}{"id": r.calls}, HTTPStatus: http.StatusCreated}
+	}
+	return &response.Response{Data: map[string]interface{}{"id": r.calls}}
+}
+
+func newOrderApp() (*gin.Engine, *orderResource) {
+	resource := &orderResource{
+		Resource:    restful.NewResourceWithDB(nil, &orderModel{}),
+		idempotency: New(NewMemoryStore()),
+	}
+	resource.RegisterMethod(restful.ListMethod, restful.HTTPMethodPost, "", resource.create)
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/orders", resource)
+	root.Mount(app.Group("/api"))
+	return app, resource
+}
+
+func post(app *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/orders", strings.NewReader(body))
+	if len(key) > 0 {
+		req.Header.Set(DefaultHeader, key)
+	}
+	app.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	app, resource := newOrderApp()
+
+	w1 := post(app, "k1", `{"amount":1}`)
+	w2 := post(app, "k1", `{"amount":1}`)
+	if resource.calls != 1 {
+		t.Errorf("replay fail, calls=%d", resource.calls)
+	}
+	if w1.Body.String() != w2.Body.String() || w2.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("replay body fail, first=%s second=%s", w1.Body.String(), w2.Body.String())
+	}
+	if w := post(app, "k1", `{"amount":2}`); w.Code != 422 {
+		t.Errorf("different body expect 422, got=%d", w.Code)
+	}
+
+	post(app, "", `{"amount":1}`)
+	post(app, "", `{"amount":1}`)
+	if resource.calls != 3 {
+		t.Errorf("request without key fail, calls=%d", resource.calls)
+	}
+
+	// 5xx 不记录，允许重试
+	post(app, "k2", `{"fail":1}`)
+	post(app, "k2", `{"fail":1}`)
+	if resource.calls != 5 {
+		t.Errorf("5xx should not be recorded, calls=%d", resource.calls)
+	}
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	app, resource := newOrderApp()
+
+	w1 := post(app, "k1", `{"created":1}`)
+	w2 := post(app, "k1", `{"created":1}`)
+	if w1.Code != http.StatusCreated || w2.Code != http.StatusCreated || w1.Body.String() != w2.Body.String() {
+		t.Errorf("replay 201 fail, first=%d %s second=%d %s", w1.Code, w1.Body.String(), w2.Code, w2.Body.String())
+	}
+
+	w1 = post(app, "k2", `{"conflict":1}`)
+	w2 = post(app, "k2", `{"conflict":1}`)
+	if resource.calls != 2 {
+		t.Errorf("replay error fail, calls=%d", resource.calls)
+	}
+	if w2.Code != http.StatusConflict || w1.Body.String() != w2.Body.String() {
+		t.Errorf("replay error fail, first=%d %s second=%d %s", w1.Code, w1.Body.String(), w2.Code, w2.Body.String())
+	}
+	if !strings.Contains(w2.Body.String(), `"code":"conflict"`) || !strings.Contains(w2.Body.String(), `"details":["amount"]`) {
+		t.Errorf("replay error body fail, got=%s", w2.Body.String())
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	app, resource := newOrderApp()
+	store := resource.idempotency.Store
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/orders", nil)
+	_ = restful.ContextWithRequestBody(c, c.Request)
+	if exists, _ := store.Acquire(c, &Record{Key: "k3", Fingerprint: fingerprint(c)}); exists != nil {
+		t.Fatalf("Acquire fail")
+	}
+	if w := post(app, "k3", ""); w.Code != 409 {
+		t.Errorf("in progress expect 409, got=%d", w.Code)
+	}
+}
+
+func TestFingerprintQuery(t *testing.T) {
+	newFingerprint := func(url string) string {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, url, strings.NewReader(`{"amount":1}`))
+		_ = restful.ContextWithRequestBody(c, c.Request)
+		return fingerprint(c)
+	}
+	// dry run 与实际写入不能共用记录
+	if newFingerprint("/api/orders/_import?dry_run=true") == newFingerprint("/api/orders/_import") {
+		t.Errorf("fingerprint should include query")
+	}
+	if newFingerprint("/api/orders?a=1&b=2") != newFingerprint("/api/orders?b=2&a=1") {
+		t.Errorf("fingerprint should ignore query order")
+	}
+}
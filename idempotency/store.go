@@ -0,0 +1,85 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Record 幂等记录
+type Record struct {
+	Key         string
+	Fingerprint string
+	// Done 为 false 表示请求仍在处理中
+	Done bool
+	// Body 处理结果，为 response.Response 的 json
+	Body []byte
+	// HTTPStatus 处理结果指定的 http 状态码，如201，为0时根据 Body 中的 status 推断
+	HTTPStatus int
+	ExpireTime time.Time
+}
+
+// Expired 是否已过期
+func (r *Record) Expired() bool {
+	return !r.ExpireTime.IsZero() && time.Now().After(r.ExpireTime)
+}
+
+// Store 幂等记录存储
+type Store interface {
+	// Acquire 占用 key，key 已被占用且未过期时返回已有记录，占用成功返回 nil
+	Acquire(context.Context, *Record) (*Record, error)
+	// Complete 保存处理结果
+	Complete(context.Context, *Record) error
+	// Release 释放占用，处理失败时调用，允许客户端重试
+	Release(context.Context, string) error
+}
+
+// MemoryStore 进程内存储，仅适用于单实例部署
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*Record),
+	}
+}
+
+func (s *MemoryStore) Acquire(ctx context.Context, record *Record) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if exists, ok := s.records[record.Key]; ok && !exists.Expired() {
+		copied := *exists
+		return &copied, nil
+	}
+	copied := *record
+	s.records[record.Key] = &copied
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *record
+	s.records[record.Key] = &copied
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// Purge 清理已过期记录
+func (s *MemoryStore) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, record := range s.records {
+		if record.Expired() {
+			delete(s.records, key)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/lookupearth/restful/event"
 	"github.com/lookupearth/restful/model"
 	"github.com/lookupearth/restful/response"
 	"gorm.io/gorm"
@@ -28,6 +29,17 @@ type IRoot interface {
 	Validate() *validator.Validate
 }
 
+// IVersionRoot 支持接口版本的 IRoot，New() 返回的实例已实现
+type IVersionRoot interface {
+	RegisterVersionResource(string, string, IController)
+	Versions() []*Version
+}
+
+// IRecoveryRoot 可设置 panic 处理方式的 IRoot，New() 返回的实例已实现，未实现时使用默认的处理方式
+type IRecoveryRoot interface {
+	GetRecovery() *Recovery
+}
+
 type ISerializer interface {
 	WithDefaults([]string) ISerializer
 	Parse(*gin.Context, []byte) error
@@ -49,6 +61,11 @@ type IController interface {
 	Print(string)
 }
 
+// IRoutes 返回 IController 的路由，设置接口版本时用于挂载到各版本下，Controller 已实现
+type IRoutes interface {
+	Routes() map[string]gin.HandlerFunc
+}
+
 // IResource 接口定义，运行时可以调用
 type IResource interface {
 	// Query 获取查询句柄，推荐优先用QueryWithContext/QueryPrimaryKey
@@ -71,6 +88,21 @@ type IResource interface {
 	GetPrimaryKey(*gin.Context) interface{}
 }
 
+// IResourceName 资源名，Resource 已实现，未实现时使用 model 的表名，见 ResourceName
+type IResourceName interface {
+	GetName() string
+}
+
+// IResourceOutput 当前请求的输出模型，Resource 已实现，见 ResourceOutput
+type IResourceOutput interface {
+	GetOutput(*gin.Context) *model.Output
+}
+
+// IResourceLookup 详情路由定位数据的db列与值，Resource 已实现，未实现时按主键解析，见 ResourceLookup
+type IResourceLookup interface {
+	GetLookup(*gin.Context) map[string]interface{}
+}
+
 type IList interface {
 	List(*gin.Context) Response
 }
@@ -127,10 +159,54 @@ type ISearchInit interface {
 	InitSearch(IResource)
 }
 
+type IStream interface {
+	Stream(*gin.Context) Response
+}
+
+type IStreamInit interface {
+	InitStream(IResource)
+}
+
+type IAggregate interface {
+	Aggregate(*gin.Context) Response
+}
+
+type IAggregateInit interface {
+	InitAggregate(IResource)
+}
+
+type IExport interface {
+	Export(*gin.Context) Response
+}
+
+type IExportInit interface {
+	InitExport(IResource)
+}
+
+type IImport interface {
+	Import(*gin.Context) Response
+}
+
+type IImportInit interface {
+	InitImport(IResource)
+}
+
+// IVersioned 按请求版本转换请求与返回数据，Resource 已实现
+type IVersioned interface {
+	TransformRequest(*gin.Context, []byte) ([]byte, error)
+	TransformResponse(*gin.Context, interface{}) (interface{}, error)
+}
+
 type IDecorator interface {
 	GetDecorators() []HandlerDecorator
 }
 
+// IEventEmitter 写操作事件发送，mixins 在写事务内调用 EmitEvent，提交后调用 NotifyEvent
+type IEventEmitter interface {
+	EmitEvent(*gorm.DB, *event.Event) error
+	NotifyEvent(*event.Event)
+}
+
 type IValidator interface {
 	Register(interface{})
 	Validate(context.Context, interface{}) *response.Error
@@ -0,0 +1,369 @@
+// Package jsonpatch 实现 JSON Patch (RFC 6902) 与 JSON Merge Patch (RFC 7396)
+//
+//	文档统一使用 Decode 解析，数字保留为 json.Number，避免大整数精度丢失
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentTypeJSONPatch  = "application/json-patch+json"
+	ContentTypeMergePatch = "application/merge-patch+json"
+)
+
+var (
+	// ErrInvalidPatch patch 格式错误
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrTestFailed test 操作未通过
+	ErrTestFailed = errors.New("test operation failed")
+	// ErrPathNotFound 路径不存在
+	ErrPathNotFound = errors.New("path not found")
+)
+
+// Decode 解析json，数字解析为 json.Number
+func Decode(b []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// MergePatch 按 RFC 7396 将 patch 合并到 doc
+func MergePatch(doc interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	docObj, ok := doc.(map[string]interface{})
+	if !ok {
+		docObj = make(map[string]interface{})
+	} else {
+		docObj = copyObject(docObj)
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(docObj, key)
+			continue
+		}
+		docObj[key] = MergePatch(docObj[key], value)
+	}
+	return docObj
+}
+
+// Operation JSON Patch 操作
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ParsePatch 解析 JSON Patch 文档
+func ParsePatch(b []byte) ([]Operation, error) {
+	ops := make([]Operation, 0)
+	if err := json.Unmarshal(b, &ops); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+	}
+	for _, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("%w: %s operation need a value", ErrInvalidPatch, op.Op)
+			}
+		case "move", "copy":
+			if _, err := parsePointer(op.From); err != nil {
+				return nil, err
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("%w: unknown operation %s", ErrInvalidPatch, op.Op)
+		}
+		if _, err := parsePointer(op.Path); err != nil {
+			return nil, err
+		}
+	}
+	return ops, nil
+}
+
+// Apply 按 RFC 6902 依次执行操作，任一操作失败返回错误，doc 不会被修改
+func Apply(doc interface{}, ops []Operation) (interface{}, error) {
+	doc = deepCopy(doc)
+	var err error
+	for _, op := range ops {
+		path, _ := parsePointer(op.Path)
+		switch op.Op {
+		case "add":
+			value, err2 := Decode(op.Value)
+			if err2 != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err2.Error())
+			}
+			doc, err = add(doc, path, value)
+		case "remove":
+			doc, _, err = remove(doc, path)
+		case "replace":
+			value, err2 := Decode(op.Value)
+			if err2 != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err2.Error())
+			}
+			doc, _, err = remove(doc, path)
+			if err == nil {
+				doc, err = add(doc, path, value)
+			}
+		case "move":
+			from, _ := parsePointer(op.From)
+			if isPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("%w: cannot move %s into its child %s", ErrInvalidPatch, op.From, op.Path)
+			}
+			var value interface{}
+			doc, value, err = remove(doc, from)
+			if err == nil {
+				doc, err = add(doc, path, value)
+			}
+		case "copy":
+			from, _ := parsePointer(op.From)
+			var value interface{}
+			value, err = get(doc, from)
+			if err == nil {
+				doc, err = add(doc, path, deepCopy(value))
+			}
+		case "test":
+			expect, err2 := Decode(op.Value)
+			if err2 != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err2.Error())
+			}
+			var value interface{}
+			value, err = get(doc, path)
+			if err == nil && !Equal(value, expect) {
+				err = fmt.Errorf("%w: %s", ErrTestFailed, op.Path)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// Equal 比较两个 Decode 得到的值，数字按数值比较
+func Equal(a, b interface{}) bool {
+	na, okA := a.(json.Number)
+	nb, okB := b.(json.Number)
+	if okA && okB {
+		if na == nb {
+			return true
+		}
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		return errA == nil && errB == nil && fa == fb
+	}
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for k, v := range va {
+			if v2, ok := vb[k]; !ok || !Equal(v, v2) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !Equal(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// parsePointer 解析 JSON Pointer (RFC 6901)
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: invalid json pointer %s", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	current := doc
+	for _, token := range path {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			value, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, token)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(v)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = v[index]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, token)
+		}
+	}
+	return current, nil
+}
+
+// add 在 path 处添加 value，path 为空时替换整个文档
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch v := parent.(type) {
+	case map[string]interface{}:
+		v[token] = value
+		return doc, nil
+	case []interface{}:
+		index := len(v)
+		if token != "-" {
+			index, err = arrayIndex(token, len(v))
+			if err != nil {
+				return nil, err
+			}
+		}
+		v = append(v, nil)
+		copy(v[index+1:], v[index:])
+		v[index] = value
+		return set(doc, path[:len(path)-1], v)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPathNotFound, token)
+}
+
+// remove 删除 path 处的值并返回
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, doc, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, nil, err
+	}
+	token := path[len(path)-1]
+	switch v := parent.(type) {
+	case map[string]interface{}:
+		value, ok := v[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %s", ErrPathNotFound, token)
+		}
+		delete(v, token)
+		return doc, value, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(v)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := v[index]
+		v = append(v[:index:index], v[index+1:]...)
+		doc, err = set(doc, path[:len(path)-1], v)
+		return doc, value, err
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrPathNotFound, token)
+}
+
+// set 替换 path 处的值，用于数组长度变化后回写
+func set(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch v := parent.(type) {
+	case map[string]interface{}:
+		v[token] = value
+	case []interface{}:
+		index, err := arrayIndex(token, len(v)-1)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = value
+	}
+	return doc, nil
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	if len(token) > 1 && token[0] == '0' {
+		return 0, fmt.Errorf("%w: invalid array index %s", ErrInvalidPatch, token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("%w: invalid array index %s", ErrInvalidPatch, token)
+	}
+	if index > max {
+		return 0, fmt.Errorf("%w: array index %s out of range", ErrPathNotFound, token)
+	}
+	return index, nil
+}
+
+func copyObject(obj map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		copied[k] = v
+	}
+	return copied
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			copied[k] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, item := range value {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	}
+	return v
+}
@@ -0,0 +1,124 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func mustDecode(t *testing.T, s string) interface{} {
+	v, err := Decode([]byte(s))
+	if err != nil {
+		t.Fatalf("Decode fail, input=%s error=%v", s, err)
+	}
+	return v
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []struct {
+		doc    string
+		patch  string
+		expect string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, c := range cases {
+		doc := mustDecode(t, c.doc)
+		result := MergePatch(doc, mustDecode(t, c.patch))
+		if !Equal(result, mustDecode(t, c.expect)) {
+			b, _ := json.Marshal(result)
+			t.Errorf("TestMergePatch fail, doc=%s patch=%s expect=%s got=%s", c.doc, c.patch, c.expect, b)
+		}
+		if !Equal(doc, mustDecode(t, c.doc)) {
+			t.Errorf("TestMergePatch modified doc, doc=%s", c.doc)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		doc    string
+		patch  string
+		expect string
+	}{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":{"a":1}}`, `[{"op":"copy","from":"/foo","path":"/bar"}]`, `{"foo":{"a":1},"bar":{"a":1}}`},
+		{`{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2.0}]`,
+			`{"baz":"qux","foo":["a",2,"c"]}`},
+		{`{"/":1,"m~n":2}`, `[{"op":"replace","path":"/~1","value":3},{"op":"remove","path":"/m~0n"}]`, `{"/":3}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"","value":{"baz":1}}]`, `{"baz":1}`},
+	}
+	for _, c := range cases {
+		ops, err := ParsePatch([]byte(c.patch))
+		if err != nil {
+			t.Errorf("TestApply ParsePatch fail, patch=%s error=%v", c.patch, err)
+			continue
+		}
+		doc := mustDecode(t, c.doc)
+		result, err := Apply(doc, ops)
+		if err != nil {
+			t.Errorf("TestApply fail, doc=%s patch=%s error=%v", c.doc, c.patch, err)
+			continue
+		}
+		if !Equal(result, mustDecode(t, c.expect)) {
+			b, _ := json.Marshal(result)
+			t.Errorf("TestApply fail, doc=%s patch=%s expect=%s got=%s", c.doc, c.patch, c.expect, b)
+		}
+		if !Equal(doc, mustDecode(t, c.doc)) {
+			t.Errorf("TestApply modified doc, doc=%s", c.doc)
+		}
+	}
+}
+
+func TestApplyError(t *testing.T) {
+	cases := []struct {
+		doc    string
+		patch  string
+		expect error
+	}{
+		{`{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ErrTestFailed},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ErrPathNotFound},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, ErrPathNotFound},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/2","value":"qux"}]`, ErrPathNotFound},
+		{`{"foo":["bar"]}`, `[{"op":"remove","path":"/foo/01"}]`, ErrInvalidPatch},
+		{`{"foo":{"a":1}}`, `[{"op":"move","from":"/foo","path":"/foo/b"}]`, ErrInvalidPatch},
+	}
+	for _, c := range cases {
+		ops, err := ParsePatch([]byte(c.patch))
+		if err == nil {
+			_, err = Apply(mustDecode(t, c.doc), ops)
+		}
+		if !errors.Is(err, c.expect) {
+			t.Errorf("TestApplyError fail, patch=%s expect=%v got=%v", c.patch, c.expect, err)
+		}
+	}
+
+	invalid := []string{
+		`{"op":"add"}`,
+		`[{"op":"unknown","path":"/a"}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"remove","path":"a"}]`,
+		`[{"op":"copy","from":"a","path":"/a"}]`,
+	}
+	for _, patch := range invalid {
+		if _, err := ParsePatch([]byte(patch)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("TestApplyError ParsePatch fail, patch=%s expect=%v got=%v", patch, ErrInvalidPatch, err)
+		}
+	}
+}
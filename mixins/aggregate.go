@@ -0,0 +1,314 @@
+package mixins
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/model"
+	"github.com/lookupearth/restful/response"
+)
+
+const (
+	// GroupByParam 分组参数，如 ?group_by=status,create_time:day
+	GroupByParam = "group_by"
+	// MetricsParam 统计参数，如 ?metrics=count,sum:amount
+	MetricsParam = "metrics"
+)
+
+// bucketFormats 时间分桶格式，按数据库方言区分
+var bucketFormats = map[string]map[string]string{
+	"hour": {
+		"mysql":    "%Y-%m-%d %H:00:00",
+		"sqlite":   "%Y-%m-%d %H:00:00",
+		"postgres": "YYYY-MM-DD HH24:00:00",
+	},
+	"day": {
+		"mysql":    "%Y-%m-%d",
+		"sqlite":   "%Y-%m-%d",
+		"postgres": "YYYY-MM-DD",
+	},
+	"month": {
+		"mysql":    "%Y-%m",
+		"sqlite":   "%Y-%m",
+		"postgres": "YYYY-MM",
+	},
+	"year": {
+		"mysql":    "%Y",
+		"sqlite":   "%Y",
+		"postgres": "YYYY",
+	},
+}
+
+// aggregateFuncs 支持的统计函数
+var aggregateFuncs = map[string]string{
+	"count": "COUNT",
+	"sum":   "SUM",
+	"avg":   "AVG",
+	"min":   "MIN",
+	"max":   "MAX",
+}
+
+type IAggregateBefore interface {
+	AggregateBefore(*gin.Context, map[string]string) error
+}
+
+// IListFilter ListMethod 提供的过滤与排序，AggregateMethod/ExportMethod 与 ListMethod 同时使用时复用
+type IListFilter interface {
+	FilterQuery(*gin.Context, *gorm.DB, map[string]string) (*gorm.DB, *response.Error)
+	SearchQuery(*gorm.DB, string) *gorm.DB
+	OrderQuery(*gorm.DB, string, string) *gorm.DB
+}
+
+// AggregateMethod 分组统计，路由为 GET <resource>/_aggregate
+//
+//	?group_by=status,create_time:day&metrics=count,sum:amount
+//	过滤条件与 ListMethod 相同（SearchParams、field__op=value、search），
+//	field.Time/field.Timestamp 字段支持 hour/day/month/year 分桶。
+type AggregateMethod struct {
+	// GroupFields 允许分组的json字段
+	GroupFields []string
+	// MetricFields 允许 sum/avg/min/max/count 的json字段
+	MetricFields []string
+	// Limit 最多返回的分组数，默认1000，返回的 total 为全部分组数
+	Limit      int
+	Decorators []restful.HandlerDecorator
+
+	handler  restful.HandlerFunc
+	instance interface{}
+}
+
+func (c *AggregateMethod) InitAggregate(resource restful.IResource) {
+	c.instance = resource
+	c.handler = restful.InstallDecorators(c.aggregate, c.Decorators)
+	if c.Limit == 0 {
+		c.Limit = 1000
+	}
+}
+
+// column 获取允许的字段，返回对应的 model 字段
+func (c *AggregateMethod) column(m *model.Model, allowed []string, key string) (*model.Field, error) {
+	if !containsString(allowed, key) {
+		return nil, fmt.Errorf("field %s is not allowed", key)
+	}
+	name, ok := m.Json2Name[key]
+	if !ok || m.Name2Field[name].DBKey == "" {
+		return nil, fmt.Errorf("unknown field %s", key)
+	}
+	return m.Name2Field[name], nil
+}
+
+// groupBy 解析分组参数，返回 select 表达式与 group 表达式
+func (c *AggregateMethod) groupBy(query *gorm.DB, m *model.Model, value string) ([]string, []string, error) {
+	selects := make([]string, 0)
+	groups := make([]string, 0)
+	for _, item := range splitParam(value) {
+		key, bucket, _ := strings.Cut(item, ":")
+		f, err := c.column(m, c.GroupFields, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		expr := query.Statement.Quote(f.DBKey)
+		if bucket != "" {
+			if !isTimeType(f.FieldType) {
+				return nil, nil, fmt.Errorf("field %s is not a time field", key)
+			}
+			expr, err = bucketExpr(query.Dialector.Name(), expr, bucket)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		selects = append(selects, expr+" AS "+query.Statement.Quote(key))
+		groups = append(groups, expr)
+	}
+	return selects, groups, nil
+}
+
+// metrics 解析统计参数，返回 select 表达式与结果字段名
+func (c *AggregateMethod) metrics(query *gorm.DB, m *model.Model, value string) ([]string, []string, error) {
+	items := splitParam(value)
+	if len(items) == 0 {
+		items = []string{"count"}
+	}
+	selects := make([]string, 0)
+	aliases := make([]string, 0)
+	for _, item := range items {
+		fn, key, _ := strings.Cut(item, ":")
+		sqlFn, ok := aggregateFuncs[fn]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown metric %s", fn)
+		}
+		if key == "" {
+			if fn != "count" {
+				return nil, nil, fmt.Errorf("metric %s need a field", fn)
+			}
+			selects = append(selects, "COUNT(*) AS "+query.Statement.Quote("count"))
+			aliases = append(aliases, "count")
+			continue
+		}
+		f, err := c.column(m, c.MetricFields, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if (fn == "sum" || fn == "avg") && !isNumberType(f.FieldType) {
+			return nil, nil, fmt.Errorf("metric %s need a number field, got %s", fn, key)
+		}
+		alias := fn + "_" + key
+		selects = append(selects, sqlFn+"("+query.Statement.Quote(f.DBKey)+") AS "+query.Statement.Quote(alias))
+		aliases = append(aliases, alias)
+	}
+	return selects, aliases, nil
+}
+
+func (c *AggregateMethod) aggregate(ctx *gin.Context) restful.Response {
+	resource := restful.ResourceFromContext(ctx)
+
+	params := restful.GetQuery(ctx)
+	// before处理
+	before, ok := c.instance.(IAggregateBefore)
+	if ok {
+		err := before.AggregateBefore(ctx, params)
+		if err != nil {
+			return response.NewError(500, err)
+		}
+	}
+
+	m := resource.GetModel()
+	query := resource.QueryWithContext(ctx)
+	// 复用 ListMethod 的过滤条件
+	if filter, ok := c.instance.(IListFilter); ok {
+		var err *response.Error
+		query, err = filter.FilterQuery(ctx, query, params)
+		if err != nil {
+			return err
+		}
+		query = withSearch(query, filter.SearchQuery(resource.Query(), params["search"]))
+	}
+
+	groupSelects, groups, err := c.groupBy(query, m, params[GroupByParam])
+	if err != nil {
+		return response.NewError(400, err)
+	}
+	metricSelects, metrics, err := c.metrics(query, m, params[MetricsParam])
+	if err != nil {
+		return response.NewError(400, err)
+	}
+
+	query = query.Select(strings.Join(append(groupSelects, metricSelects...), ", "))
+	for _, group := range groups {
+		query = query.Group(group).Order(group)
+	}
+	// 分组查询同时用于统计分组总数，需可复用
+	query = query.Session(&gorm.Session{})
+	rows := make([]map[string]interface{}, 0)
+	result := query.Limit(c.Limit).Scan(&rows)
+	restful.CheckDBResult(result)
+	for _, row := range rows {
+		for key, value := range row {
+			row[key] = normalizeValue(value, containsString(metrics, key))
+		}
+	}
+
+	// 分组数达到 Limit 时返回结果被截断，按分组子查询统计分组总数
+	total := int64(len(rows))
+	if len(groups) > 0 && len(rows) >= c.Limit {
+		result := resource.QueryWithContext(ctx).Table("(?) AS "+query.Statement.Quote("groups"), query).Count(&total)
+		restful.CheckDBResult(result)
+	}
+	return &response.Response{
+		Msg:    "",
+		Status: 0,
+		Data:   rows,
+		Total:  &total,
+	}
+}
+
+// Aggregate 分组统计
+func (c *AggregateMethod) Aggregate(ctx *gin.Context) restful.Response {
+	return c.handler(ctx)
+}
+
+func bucketExpr(dialect string, column string, bucket string) (string, error) {
+	formats, ok := bucketFormats[bucket]
+	if !ok {
+		return "", fmt.Errorf("unknown bucket %s", bucket)
+	}
+	format, ok := formats[dialect]
+	if !ok {
+		return "", fmt.Errorf("bucket is not supported by %s", dialect)
+	}
+	switch dialect {
+	case "mysql":
+		return fmt.Sprintf("DATE_FORMAT(%s, '%s')", column, format), nil
+	case "postgres":
+		return fmt.Sprintf("to_char(%s, '%s')", column, format), nil
+	default:
+		return fmt.Sprintf("strftime('%s', %s)", format, column), nil
+	}
+}
+
+// normalizeValue 驱动返回的 []byte 转为字符串，统计值转为数字
+func normalizeValue(value interface{}, metric bool) interface{} {
+	var s string
+	switch v := value.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return value
+	}
+	if metric {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return json.Number(s)
+		}
+	}
+	return s
+}
+
+func splitParam(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isTimeType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t.ConvertibleTo(reflect.TypeOf(time.Time{}))
+}
+
+func isNumberType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
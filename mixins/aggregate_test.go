@@ -0,0 +1,129 @@
+package mixins
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/field"
+	"github.com/lookupearth/restful/model"
+)
+
+type AggregateCase struct {
+	ID         int64      `gorm:"column:id;primaryKey" json:"id"`
+	Status     int32      `gorm:"column:status" json:"status"`
+	Name       string     `gorm:"column:name" json:"name"`
+	Amount     float64    `gorm:"column:amount" json:"amount"`
+	CreateTime field.Time `gorm:"column:create_time" json:"createTime"`
+	UpdateTime *time.Time `gorm:"column:update_time" json:"updateTime"`
+	Score      *int64     `gorm:"column:score" json:"score"`
+}
+
+func TestAggregateQuery(t *testing.T) {
+	db := dryRunDB(t)
+	m := model.NewModel(&AggregateCase{})
+	c := &AggregateMethod{
+		GroupFields:  []string{"status", "createTime", "updateTime"},
+		MetricFields: []string{"amount", "name", "score"},
+	}
+	query := db.Model(&AggregateCase{})
+
+	selects, groups, err := c.groupBy(query, m, "status, createTime:day")
+	if err != nil {
+		t.Fatalf("TestAggregateQuery groupBy fail, error=%v", err)
+	}
+	expectSelects := []string{"`status` AS `status`", "DATE_FORMAT(`create_time`, '%Y-%m-%d') AS `createTime`"}
+	if !reflect.DeepEqual(selects, expectSelects) {
+		t.Errorf("TestAggregateQuery groupBy fail, expect=%v got=%v", expectSelects, selects)
+	}
+	expectGroups := []string{"`status`", "DATE_FORMAT(`create_time`, '%Y-%m-%d')"}
+	if !reflect.DeepEqual(groups, expectGroups) {
+		t.Errorf("TestAggregateQuery groupBy fail, expect=%v got=%v", expectGroups, groups)
+	}
+
+	selects, aliases, err := c.metrics(query, m, "count,sum:amount,max:name")
+	if err != nil {
+		t.Fatalf("TestAggregateQuery metrics fail, error=%v", err)
+	}
+	expectSelects = []string{"COUNT(*) AS `count`", "SUM(`amount`) AS `sum_amount`", "MAX(`name`) AS `max_name`"}
+	if !reflect.DeepEqual(selects, expectSelects) {
+		t.Errorf("TestAggregateQuery metrics fail, expect=%v got=%v", expectSelects, selects)
+	}
+	if !reflect.DeepEqual(aliases, []string{"count", "sum_amount", "max_name"}) {
+		t.Errorf("TestAggregateQuery metrics alias fail, got=%v", aliases)
+	}
+	if selects, _, _ := c.metrics(query, m, ""); !reflect.DeepEqual(selects, []string{"COUNT(*) AS `count`"}) {
+		t.Errorf("TestAggregateQuery default metrics fail, got=%v", selects)
+	}
+
+	// 指针类型的时间与数字字段
+	if selects, _, err := c.groupBy(query, m, "updateTime:month"); err != nil || selects[0] != "DATE_FORMAT(`update_time`, '%Y-%m') AS `updateTime`" {
+		t.Errorf("TestAggregateQuery pointer time fail, got=%v error=%v", selects, err)
+	}
+	if _, _, err := c.metrics(query, m, "sum:score"); err != nil {
+		t.Errorf("TestAggregateQuery pointer number fail, error=%v", err)
+	}
+
+	for _, groupBy := range []string{"name", "id", "status:day", "createTime:week"} {
+		if _, _, err := c.groupBy(query, m, groupBy); err == nil {
+			t.Errorf("TestAggregateQuery groupBy %s should fail", groupBy)
+		}
+	}
+	for _, metric := range []string{"sum", "sum:status", "sum:name", "median:amount"} {
+		if _, _, err := c.metrics(query, m, metric); err == nil {
+			t.Errorf("TestAggregateQuery metrics %s should fail", metric)
+		}
+	}
+}
+
+func TestNormalizeValue(t *testing.T) {
+	if v := normalizeValue([]byte("12.50"), true); v != json.Number("12.50") {
+		t.Errorf("TestNormalizeValue metric fail, got=%#v", v)
+	}
+	if v := normalizeValue([]byte("12"), false); v != "12" {
+		t.Errorf("TestNormalizeValue group fail, got=%#v", v)
+	}
+	if v := normalizeValue(int64(3), true); v != int64(3) {
+		t.Errorf("TestNormalizeValue int fail, got=%#v", v)
+	}
+}
+
+type aggregateCase struct {
+	*restful.Resource
+	*AggregateMethod
+}
+
+func TestAggregateTotal(t *testing.T) {
+	db, s := newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+		if strings.HasPrefix(query, "SELECT count(*)") {
+			return &scriptResult{Columns: []string{"count(*)"}, Rows: [][]driver.Value{{int64(5)}}}
+		}
+		return &scriptResult{Columns: []string{"status", "count"}, Rows: [][]driver.Value{{int64(1), int64(3)}, {int64(2), int64(4)}}}
+	})
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/items", &aggregateCase{
+		Resource:        restful.NewResourceWithDB(db, &AggregateCase{}),
+		AggregateMethod: &AggregateMethod{GroupFields: []string{"status"}, Limit: 2},
+	})
+	root.Mount(app.Group("/api"))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/items/_aggregate?group_by=status", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"total":5`) {
+		t.Errorf("TestAggregateTotal fail, got=%d %s", w.Code, w.Body.String())
+	}
+	// 分组总数按分组子查询统计，不受 Limit 影响
+	expect := "SELECT count(*) FROM (SELECT `status` AS `status`, COUNT(*) AS `count` FROM `aggregate_cases` GROUP BY `status` ORDER BY `status`) AS `groups`"
+	if sql := s.SQL(); len(sql) != 2 || sql[1] != expect {
+		t.Errorf("TestAggregateTotal count sql fail, sql=%v", sql)
+	}
+}
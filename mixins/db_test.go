@@ -0,0 +1,187 @@
+package mixins
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB 不连接数据库的 mysql DryRun 实例，用于校验生成的SQL
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "root:root@tcp(127.0.0.1:3306)/demo",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open fail, error=%v", err)
+	}
+	return db
+}
+
+// scriptResult 脚本数据库对一条SQL的返回
+type scriptResult struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+	LastInsertID int64
+	// Err 执行失败时返回的错误
+	Err error
+}
+
+// scriptDB 按脚本返回结果的数据库，用于不依赖真实数据库的 handler 测试，记录执行过的SQL
+type scriptDB struct {
+	mu      sync.Mutex
+	sql     []string
+	vars    [][]driver.Value
+	handler func(query string, args []driver.Value) *scriptResult
+}
+
+// newScriptDB 创建脚本数据库，handler 返回nil时视为没有数据
+func newScriptDB(t *testing.T, handler func(query string, args []driver.Value) *scriptResult) (*gorm.DB, *scriptDB) {
+	s := &scriptDB{handler: handler}
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sql.OpenDB(s),
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("gorm.Open fail, error=%v", err)
+	}
+	return db, s
+}
+
+// SQL 已执行的SQL，包括 BEGIN/COMMIT/ROLLBACK
+func (s *scriptDB) SQL() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.sql...)
+}
+
+// Vars 已执行SQL的参数，与 SQL 一一对应
+func (s *scriptDB) Vars() [][]driver.Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]driver.Value{}, s.vars...)
+}
+
+// Find 第一条以 prefix 开头的SQL的参数，不存在时返回nil
+func (s *scriptDB) Find(prefix string) []driver.Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, query := range s.sql {
+		if strings.HasPrefix(query, prefix) {
+			return s.vars[i]
+		}
+	}
+	return nil
+}
+
+func (s *scriptDB) run(query string, args []driver.NamedValue) *scriptResult {
+	values := make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+	s.mu.Lock()
+	s.sql = append(s.sql, query)
+	s.vars = append(s.vars, values)
+	s.mu.Unlock()
+	if res := s.handler(query, values); res != nil {
+		return res
+	}
+	return &scriptResult{}
+}
+
+func (s *scriptDB) Connect(context.Context) (driver.Conn, error) {
+	return &scriptConn{db: s}, nil
+}
+
+func (s *scriptDB) Driver() driver.Driver {
+	return s
+}
+
+func (s *scriptDB) Open(string) (driver.Conn, error) {
+	return &scriptConn{db: s}, nil
+}
+
+type scriptConn struct {
+	db *scriptDB
+}
+
+func (c *scriptConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *scriptConn) Close() error {
+	return nil
+}
+
+func (c *scriptConn) Begin() (driver.Tx, error) {
+	if res := c.db.run("BEGIN", nil); res.Err != nil {
+		return nil, res.Err
+	}
+	return c, nil
+}
+
+func (c *scriptConn) Commit() error {
+	return c.db.run("COMMIT", nil).Err
+}
+
+func (c *scriptConn) Rollback() error {
+	return c.db.run("ROLLBACK", nil).Err
+}
+
+func (c *scriptConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res := c.db.run(query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return scriptExecResult{res}, nil
+}
+
+func (c *scriptConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res := c.db.run(query, args)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	return &scriptRows{res: res}, nil
+}
+
+type scriptExecResult struct {
+	res *scriptResult
+}
+
+func (r scriptExecResult) LastInsertId() (int64, error) {
+	return r.res.LastInsertID, nil
+}
+
+func (r scriptExecResult) RowsAffected() (int64, error) {
+	return r.res.RowsAffected, nil
+}
+
+type scriptRows struct {
+	res *scriptResult
+	i   int
+}
+
+func (r *scriptRows) Columns() []string {
+	return r.res.Columns
+}
+
+func (r *scriptRows) Close() error {
+	return nil
+}
+
+func (r *scriptRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.res.Rows) {
+		return io.EOF
+	}
+	copy(dest, r.res.Rows[r.i])
+	r.i++
+	return nil
+}
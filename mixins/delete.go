@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
 	"github.com/lookupearth/restful/response"
 )
 
@@ -44,10 +45,28 @@ func (c *DeleteMethod) delete(ctx *gin.Context) restful.Response {
 
 	// GORM 实例化
 	query := resource.QueryPrimaryKey(ctx)
+	query = query.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			query.Rollback()
+			panic(r)
+		}
+	}()
 
+	pk := eventPrimaryKey(ctx, query, resource)
 	data := model.New()
 	result := query.Delete(data)
 	restful.CheckDBResult(result)
+	// 事件与数据在同一事务内写入
+	e, err := emitEvent(c.instance, query, event.ActionDelete, pk, model, nil)
+	if err != nil {
+		query.Rollback()
+		return response.NewError(500, err)
+	}
+	if result := query.Commit(); result.Error != nil {
+		return restful.NewDBResultError(result)
+	}
+	notifyEvent(c.instance, e)
 
 	// after处理
 	after, ok := c.instance.(IDeleteAfter)
@@ -0,0 +1,90 @@
+package mixins
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
+	"github.com/lookupearth/restful/model"
+	"gorm.io/gorm"
+)
+
+// emitEvent 在写事务内发出事件，instance 未实现 IEventEmitter 时忽略
+func emitEvent(instance interface{}, tx *gorm.DB, action event.Action, pk interface{}, m *model.Model, data map[string]interface{}) (*event.Event, error) {
+	emitter, ok := instance.(restful.IEventEmitter)
+	if !ok {
+		return nil, nil
+	}
+	e := event.NewEvent("", action, pk)
+	if data != nil {
+		e.Fields, e.Data = changedFields(m, data)
+	}
+	// Sink 不应继承写操作的 Model 与主键条件
+	return e, emitter.EmitEvent(tx.Session(&gorm.Session{NewDB: true}), e)
+}
+
+// eventPrimaryKey 事件使用的主键，通过 LookupField 定位时在事务内读取真实主键，数据不存在时返回nil
+func eventPrimaryKey(ctx *gin.Context, tx *gorm.DB, resource restful.IResource) interface{} {
+	m := resource.GetModel()
+	lookup := restful.ResourceLookup(resource, ctx)
+	columns := make([]string, 0, len(lookup))
+	for column := range lookup {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	primary := append([]string{}, m.PrimaryKeys...)
+	sort.Strings(primary)
+	if equalStrings(columns, primary) {
+		return m.KeyValue(lookup)
+	}
+	data := m.New()
+	query := m.WhereKey(tx.Session(&gorm.Session{NewDB: true}).Model(m.New()), columns, lookup)
+	result := query.Select(m.PrimaryKeys).Limit(1).Find(data)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+	return m.PrimaryKeyValue(data)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// notifyEvent 写事务提交后通知事件
+func notifyEvent(instance interface{}, e *event.Event) {
+	emitter, ok := instance.(restful.IEventEmitter)
+	if !ok || e == nil {
+		return
+	}
+	emitter.NotifyEvent(e)
+}
+
+// changedFields 将db列为key的数据转换为json字段
+func changedFields(m *model.Model, data map[string]interface{}) ([]string, map[string]interface{}) {
+	fields := make([]string, 0, len(data))
+	jsonData := make(map[string]interface{}, len(data))
+	for column, value := range data {
+		name, ok := m.Column2Name[column]
+		if !ok {
+			continue
+		}
+		jsonKey, ok := m.Name2Json[name]
+		if !ok {
+			continue
+		}
+		fields = append(fields, jsonKey)
+		jsonData[jsonKey] = value
+	}
+	sort.Strings(fields)
+	return fields, jsonData
+}
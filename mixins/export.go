@@ -0,0 +1,282 @@
+package mixins
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/model"
+	"github.com/lookupearth/restful/response"
+)
+
+const (
+	// FormatParam 导出格式参数，csv（默认）或 ndjson
+	FormatParam = "format"
+
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+type IExportBefore interface {
+	ExportBefore(*gin.Context, map[string]string) error
+}
+
+// ExportMethod 流式导出，路由为 GET <resource>/_export?format=csv|ndjson
+//
+//	过滤、检索与排序与 ListMethod 相同，按 Rows 逐行读取，每 BatchSize 行处理并 flush 一次，不会缓存全部数据。
+//	每批数据与 ListMethod 的返回一致：经过 ListAfter 加工、虚拟字段与输出模型（含接口版本的输出模型）转换。
+//	导出字段默认为输出模型或 model 中全部json字段（按结构体顺序），可通过 Fields 或 ?fields= 限定。
+type ExportMethod struct {
+	Fields []string
+	// OutputModel 输出模型，未设置时使用 Resource 的输出模型
+	OutputModel interface{}
+	// BatchSize flush 间隔行数，默认1000
+	BatchSize  int
+	Decorators []restful.HandlerDecorator
+
+	output   *model.Output
+	handler  restful.HandlerFunc
+	instance interface{}
+}
+
+func (c *ExportMethod) InitExport(resource restful.IResource) {
+	c.instance = resource
+	c.handler = restful.InstallDecorators(c.export, c.Decorators)
+	if c.BatchSize == 0 {
+		c.BatchSize = 1000
+	}
+	if c.OutputModel != nil {
+		c.output = model.NewOutput(c.OutputModel)
+	}
+}
+
+// jsonFields 按结构体顺序返回可导出的json字段，虚拟字段排在最后
+func jsonFields(m *model.Model) []string {
+	fields := make([]string, 0)
+	for _, name := range m.Names {
+		if key, ok := m.Name2Json[name]; ok {
+			fields = append(fields, key)
+		}
+	}
+	return append(fields, m.ComputedKeys()...)
+}
+
+func (c *ExportMethod) export(ctx *gin.Context) restful.Response {
+	resource := restful.ResourceFromContext(ctx)
+
+	params := restful.GetQuery(ctx)
+	// before处理
+	before, ok := c.instance.(IExportBefore)
+	if ok {
+		err := before.ExportBefore(ctx, params)
+		if err != nil {
+			return response.NewError(500, err)
+		}
+	}
+
+	format := params[FormatParam]
+	if format == "" {
+		format = FormatCSV
+	}
+	if format != FormatCSV && format != FormatNDJSON {
+		return response.NewErrorFromMsg(400, fmt.Sprintf("unknown format %s", format))
+	}
+
+	m := resource.GetModel()
+	out := outputModel(ctx, c.output)
+	fields := ParseFields(ctx)
+	if fields == nil {
+		fields = c.Fields
+	}
+	// 可导出的字段与 exportObjects 输出的字段一致，具名嵌入结构体的字段为 <key>.<子字段>
+	keys := jsonFields(m)
+	if out != nil {
+		keys = out.JsonKeys()
+	}
+	if fields == nil {
+		fields = keys
+	}
+	for _, f := range fields {
+		if !containsString(keys, f) {
+			return response.NewErrorFromMsg(400, fmt.Sprintf("unknown field %s", f))
+		}
+	}
+
+	query := resource.QueryWithContext(ctx)
+	// 复用 ListMethod 的过滤与排序
+	if filter, ok := c.instance.(IListFilter); ok {
+		var err *response.Error
+		query, err = filter.FilterQuery(ctx, query, params)
+		if err != nil {
+			return err
+		}
+		query = withSearch(query, filter.SearchQuery(resource.Query(), params["search"]))
+		query = filter.OrderQuery(query, params["orderBy"], params["search"])
+	}
+
+	return &exportResponse{
+		method:   c,
+		resource: resource,
+		query:    query,
+		format:   format,
+		fields:   fields,
+		output:   out,
+	}
+}
+
+// Export 流式导出
+func (c *ExportMethod) Export(ctx *gin.Context) restful.Response {
+	return c.handler(ctx)
+}
+
+type exportResponse struct {
+	method   *ExportMethod
+	resource restful.IResource
+	query    *gorm.DB
+	format   string
+	fields   []string
+	output   *model.Output
+}
+
+func (s *exportResponse) Response(ctx *gin.Context) {
+	m := s.resource.GetModel()
+	rows, err := s.query.Model(m.New()).Rows()
+	if err != nil {
+		response.NewError(500, err).Response(ctx)
+		return
+	}
+	defer rows.Close()
+
+	header := ctx.Writer.Header()
+	filename := restful.ResourceName(s.resource) + "." + s.format
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if s.format == FormatCSV {
+		header.Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		header.Set("Content-Type", "application/x-ndjson")
+	}
+	ctx.Writer.WriteHeader(200)
+
+	writer := csv.NewWriter(ctx.Writer)
+	if s.format == FormatCSV {
+		if err := writer.Write(s.fields); err != nil {
+			return
+		}
+	}
+	// write 按批处理并输出，返回false时中断传输
+	write := func(batch interface{}) bool {
+		objects, err := exportObjects(ctx, s.method.instance, m, s.output, batch, s.fields)
+		if err != nil {
+			// 已开始输出，无法再返回错误响应，中断传输
+			ctx.Error(err)
+			return false
+		}
+		for _, obj := range objects {
+			if s.format == FormatCSV {
+				record := make([]string, len(s.fields))
+				for i, f := range s.fields {
+					record[i] = csvValue(obj[f])
+				}
+				err = writer.Write(record)
+			} else {
+				var line []byte
+				line, err = json.Marshal(obj)
+				if err == nil {
+					_, err = ctx.Writer.Write(append(line, '\n'))
+				}
+			}
+			if err != nil {
+				return false
+			}
+		}
+		writer.Flush()
+		ctx.Writer.Flush()
+		return true
+	}
+	batch := m.NewSlice()
+	items := reflect.ValueOf(batch).Elem()
+	for rows.Next() {
+		item := reflect.New(m.ModelType)
+		if err := s.query.ScanRows(rows, item.Interface()); err != nil {
+			ctx.Error(err)
+			return
+		}
+		items.Set(reflect.Append(items, item.Elem()))
+		if items.Len() >= s.method.BatchSize {
+			if !write(batch) {
+				return
+			}
+			batch = m.NewSlice()
+			items = reflect.ValueOf(batch).Elem()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		ctx.Error(err)
+	}
+	if items.Len() > 0 {
+		write(batch)
+	}
+	writer.Flush()
+	ctx.Writer.Flush()
+}
+
+// exportObjects 与 ListMethod 返回的数据保持一致（ListAfter 加工 + 虚拟字段 + 输出模型），仅保留导出字段
+//
+//	batch 为 model 切片的指针，未使用输出模型时具名嵌入结构体的字段按 <key>.<子字段> 导出，与导入一致
+func exportObjects(ctx *gin.Context, instance interface{}, m *model.Model, out *model.Output, batch interface{}, fields []string) ([]map[string]interface{}, error) {
+	if after, ok := instance.(IListAfter); ok {
+		var err error
+		batch, err = after.ListAfter(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// ?fields= 可能为 <key>.<子字段>，展开后再裁剪
+	data, err := outputFields(ctx, m, out, batch, nil)
+	if err != nil {
+		return nil, err
+	}
+	// 转为json结构，虚拟字段与普通字段一致地输出到 CSV
+	value, err := jsonValue(data)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("export data must be a list")
+	}
+	objects := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("export data must be an object")
+		}
+		if out == nil {
+			obj = m.FlattenNested(obj)
+		}
+		objects = append(objects, pickFields(obj, fields))
+	}
+	return objects, nil
+}
+
+// csvValue 字符串原样输出，对象与数组输出json
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
@@ -0,0 +1,93 @@
+package mixins
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful/field"
+	"github.com/lookupearth/restful/model"
+)
+
+type ExportCase struct {
+	ID     int64            `gorm:"column:id;primaryKey" json:"id"`
+	Name   string           `gorm:"column:name" json:"name"`
+	Secret string           `gorm:"column:secret" json:"-"`
+	Meta   field.JSONObject `gorm:"column:meta" json:"meta"`
+	Active bool             `gorm:"column:active" json:"active"`
+}
+
+type exportAfterCase struct{}
+
+func (exportAfterCase) ListAfter(ctx *gin.Context, data interface{}) (interface{}, error) {
+	for i := range *data.(*[]ExportCase) {
+		(*data.(*[]ExportCase))[i].Name += "!"
+	}
+	return data, nil
+}
+
+type exportOutput struct {
+	Title string `json:"title" from:"name"`
+	City  string `json:"city" from:"meta.city"`
+}
+
+func TestExportObjects(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	m := model.NewModel(&ExportCase{})
+	fields := jsonFields(m)
+	if !reflect.DeepEqual(fields, []string{"id", "name", "meta", "active"}) {
+		t.Errorf("TestExportObjects jsonFields fail, got=%v", fields)
+	}
+	batch := &[]ExportCase{{ID: 1, Name: "a,b", Secret: "x", Meta: field.JSONObject{"k": 1, "city": "bj"}, Active: true}}
+	objects, err := exportObjects(ctx, exportAfterCase{}, m, nil, batch, fields)
+	if err != nil || len(objects) != 1 {
+		t.Fatalf("TestExportObjects fail, objects=%v error=%v", objects, err)
+	}
+	record := make([]string, 0)
+	for _, f := range fields {
+		record = append(record, csvValue(objects[0][f]))
+	}
+	if !reflect.DeepEqual(record, []string{"1", "a,b!", `{"city":"bj","k":1}`, "true"}) {
+		t.Errorf("TestExportObjects csv fail, got=%v", record)
+	}
+
+	// 输出模型
+	out := model.NewOutput(&exportOutput{})
+	objects, err = exportObjects(ctx, nil, m, out, batch, out.JsonKeys())
+	if err != nil || !reflect.DeepEqual(objects, []map[string]interface{}{{"title": "a,b!", "city": "bj"}}) {
+		t.Errorf("TestExportObjects output fail, got=%v error=%v", objects, err)
+	}
+	if csvValue(nil) != "" || csvValue(json.Number("1.5")) != "1.5" {
+		t.Errorf("TestExportObjects csvValue fail")
+	}
+}
+
+type ExportAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street"`
+}
+
+type ExportNestedCase struct {
+	ID   int64         `gorm:"column:id;primaryKey" json:"id"`
+	Addr ExportAddress `gorm:"embedded;embeddedPrefix:addr_" json:"addr"`
+}
+
+func TestExportNestedFields(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/?fields=id,addr.city", nil)
+	m := model.NewModel(&ExportNestedCase{})
+	if fields := jsonFields(m); !reflect.DeepEqual(fields, []string{"id", "addr.city", "addr.street"}) {
+		t.Errorf("TestExportNestedFields jsonFields fail, got=%v", fields)
+	}
+	batch := &[]ExportNestedCase{{ID: 1, Addr: ExportAddress{City: "bj", Street: "s"}}}
+	objects, err := exportObjects(ctx, nil, m, nil, batch, ParseFields(ctx))
+	expect := []map[string]interface{}{{"id": json.Number("1"), "addr.city": "bj"}}
+	if err != nil || !reflect.DeepEqual(objects, expect) {
+		t.Errorf("TestExportNestedFields fail, got=%v error=%v", objects, err)
+	}
+}
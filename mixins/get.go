@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/model"
 	"github.com/lookupearth/restful/response"
 )
 
@@ -17,8 +18,11 @@ type IGetAfter interface {
 }
 
 type GetMethod struct {
-	Decorators []restful.HandlerDecorator
+	// OutputModel 输出模型，未设置时使用 Resource 的输出模型
+	OutputModel interface{}
+	Decorators  []restful.HandlerDecorator
 
+	output   *model.Output
 	handler  restful.HandlerFunc
 	instance interface{}
 }
@@ -26,6 +30,17 @@ type GetMethod struct {
 func (c *GetMethod) InitGet(resource restful.IResource) {
 	c.instance = resource
 	c.handler = restful.InstallDecorators(c.get, c.Decorators)
+	if c.OutputModel != nil {
+		c.output = model.NewOutput(c.OutputModel)
+	}
+}
+
+// getOutput GetMethod 设置的输出模型，写操作返回对象时与 GET 保持一致
+func (c *GetMethod) getOutput() *model.Output {
+	if c == nil {
+		return nil
+	}
+	return c.output
 }
 
 func (c *GetMethod) get(ctx *gin.Context) restful.Response {
@@ -40,9 +55,9 @@ func (c *GetMethod) get(ctx *gin.Context) restful.Response {
 		}
 	}
 
-	model := resource.GetModel()
+	m := resource.GetModel()
 	// GORM 实例化
-	data := model.New()
+	data := m.New()
 	query := resource.QueryPrimaryKey(ctx)
 	// DB Query 操作
 	result := query.First(data)
@@ -57,6 +72,11 @@ func (c *GetMethod) get(ctx *gin.Context) restful.Response {
 			return response.NewError(500, err)
 		}
 	}
+	// 虚拟字段、输出模型、稀疏字段
+	data, err := outputData(ctx, m, outputModel(ctx, c.output), data)
+	if err != nil {
+		return response.NewError(500, err)
+	}
 
 	return &response.Response{
 		Msg:    "",
@@ -0,0 +1,376 @@
+package mixins
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
+	"github.com/lookupearth/restful/model"
+	"github.com/lookupearth/restful/response"
+)
+
+// DryRunParam 试运行参数，?dry_run=true 时只校验并在事务内写入后回滚
+const DryRunParam = "dry_run"
+
+type IImportBefore interface {
+	ImportBefore(*gin.Context) error
+}
+
+type IImportAfter interface {
+	ImportAfter(*gin.Context, *ImportResult) error
+}
+
+// ImportError 单行导入错误，Line 为文件中的行号
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Total   int            `json:"total"`
+	Created int            `json:"created"`
+	Failed  int            `json:"failed"`
+	DryRun  bool           `json:"dryRun"`
+	Errors  []*ImportError `json:"errors"`
+}
+
+// ImportMethod 批量导入，路由为 POST <resource>/_import
+//
+//	支持 CSV（首行为json字段名）与 NDJSON，格式由 ?format= 或 Content-Type 决定，默认 CSV。
+//	CSV 按 ParseFromQuery 规则转换类型，空单元格视为未设置；每行都经过 Serializer 默认值与校验。
+//	数据在同一事务内按 BatchSize 批量写入，存在错误行时整体回滚并返回逐行错误，
+//	SkipInvalid 为 true 时跳过错误行，仅写入正确的行。
+type ImportMethod struct {
+	// BatchSize 每批写入行数，默认500
+	BatchSize int
+	// MaxErrors 最多返回的错误行数，默认100
+	MaxErrors   int
+	SkipInvalid bool
+	Decorators  []restful.HandlerDecorator
+
+	handler  restful.HandlerFunc
+	instance interface{}
+}
+
+func (c *ImportMethod) InitImport(resource restful.IResource) {
+	c.instance = resource
+	c.handler = restful.InstallDecorators(c.importData, c.Decorators)
+	if c.BatchSize == 0 {
+		c.BatchSize = 500
+	}
+	if c.MaxErrors == 0 {
+		c.MaxErrors = 100
+	}
+}
+
+// importRow 解析后的一行数据
+type importRow struct {
+	line int
+	// query 为 CSV 数据，body 为 NDJSON 数据
+	query map[string]string
+	body  []byte
+	err   error
+}
+
+// importFormat 根据参数或 Content-Type 判断导入格式
+func importFormat(ctx *gin.Context) (string, error) {
+	format := ctx.Query(FormatParam)
+	if format == "" {
+		switch ctx.ContentType() {
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			format = FormatNDJSON
+		default:
+			format = FormatCSV
+		}
+	}
+	if format != FormatCSV && format != FormatNDJSON {
+		return "", fmt.Errorf("unknown format %s", format)
+	}
+	return format, nil
+}
+
+// readCSV 逐行读取 CSV，首行为json字段名
+func readCSV(r io.Reader, m *model.Model, fn func(*importRow) error) error {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for i, key := range header {
+		key = strings.TrimSpace(strings.TrimPrefix(key, "\ufeff"))
+		if _, ok := m.Json2Name[key]; !ok {
+			return fmt.Errorf("unknown field %s", key)
+		}
+		header[i] = key
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line, _ := reader.FieldPos(0)
+		row := &importRow{line: line}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			row.line = parseErr.Line
+			row.err = parseErr.Err
+		} else if err != nil {
+			return err
+		} else {
+			row.query = make(map[string]string, len(header))
+			for i, value := range record {
+				if value != "" {
+					row.query[header[i]] = value
+				}
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// readNDJSON 逐行读取 NDJSON，忽略空行
+func readNDJSON(r io.Reader, fn func(*importRow) error) error {
+	reader := bufio.NewReader(r)
+	line := 0
+	for {
+		b, err := reader.ReadBytes('\n')
+		if len(b) > 0 {
+			line++
+			b = bytes.TrimSpace(b)
+			if len(b) > 0 {
+				if err := fn(&importRow{line: line, body: b}); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// importData 通过校验的一行，data 为写入数据库的数据（key 为db列），obj 为 model 指针
+type importData struct {
+	data map[string]interface{}
+	obj  interface{}
+}
+
+// parseRow 通过 Serializer 解析并校验一行
+func (c *ImportMethod) parseRow(ctx *gin.Context, resource restful.IResource, row *importRow) (*importData, error) {
+	if row.err != nil {
+		return nil, row.err
+	}
+	serializer := resource.GetSerializer(resource.GetModel())
+	var err error
+	if row.query != nil {
+		err = serializer.ParseFromQuery(ctx, row.query)
+	} else {
+		err = serializer.Parse(ctx, row.body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := serializer.Validate(ctx); err != nil {
+		return nil, err
+	}
+	data := &importData{data: serializer.ValidateData(), obj: serializer.StructData()}
+	// 以结构体批量写入，自动维护的字段需写入结构体
+	resource.GetModel().SetAutoData(data.obj, data.data)
+	return data, nil
+}
+
+// insert 批量写入，仅写入用户提交或有默认值的列，列不同的行分开写入，避免丢失数据库默认值
+func (c *ImportMethod) insert(tx *gorm.DB, m *model.Model, batch []*importData) ([]*event.Event, *response.Error) {
+	groups := make(map[string]reflect.Value)
+	keys := make([]string, 0)
+	for _, row := range batch {
+		columns := make([]string, 0, len(row.data))
+		for column := range row.data {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		key := strings.Join(columns, ",")
+		slice, ok := groups[key]
+		if !ok {
+			keys = append(keys, key)
+			slice = reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(m.ModelType)), 0, len(batch))
+		}
+		groups[key] = reflect.Append(slice, reflect.ValueOf(row.obj))
+	}
+	for _, key := range keys {
+		slice := groups[key].Interface()
+		if result := tx.Model(m.New()).Select(strings.Split(key, ",")).Create(slice); result.Error != nil {
+			return nil, restful.NewDBResultError(result)
+		}
+	}
+	// 事件与数据在同一事务内写入
+	events := make([]*event.Event, 0)
+	for _, row := range batch {
+		pk := m.PrimaryKeyValue(row.obj)
+		e, err := emitEvent(c.instance, tx, event.ActionCreate, pk, m, row.data)
+		if err != nil {
+			return nil, response.CodeInternal.Wrap(err)
+		}
+		if e != nil {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (c *ImportMethod) importData(ctx *gin.Context) restful.Response {
+	resource := restful.ResourceFromContext(ctx)
+
+	// before处理
+	before, ok := c.instance.(IImportBefore)
+	if ok {
+		err := before.ImportBefore(ctx)
+		if err != nil {
+			return response.CodeInternal.Wrap(err)
+		}
+	}
+
+	format, err := importFormat(ctx)
+	if err != nil {
+		return response.CodeBadRequest.Wrap(err)
+	}
+	dryRun, _ := strconv.ParseBool(ctx.Query(DryRunParam))
+	m := resource.GetModel()
+	result := &ImportResult{
+		DryRun: dryRun,
+		Errors: make([]*ImportError, 0),
+	}
+
+	// GORM 实例化
+	query := resource.QueryWithContext(ctx)
+	query = query.Begin()
+	// 事务内的校验（如 unique）使用同一事务查询
+	clearTx := restful.ContextWithTx(ctx, query)
+	defer func() {
+		if r := recover(); r != nil {
+			clearTx()
+			query.Rollback()
+			panic(r)
+		}
+	}()
+	events := make([]*event.Event, 0)
+	batch := make([]*importData, 0, c.BatchSize)
+	var dbErr *response.Error
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		batchEvents, err := c.insert(query.Session(&gorm.Session{NewDB: true}), m, batch)
+		if err != nil {
+			dbErr = err
+			return err
+		}
+		events = append(events, batchEvents...)
+		result.Created += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	handle := func(row *importRow) error {
+		result.Total++
+		data, err := c.parseRow(ctx, resource, row)
+		if err != nil {
+			result.Failed++
+			if len(result.Errors) < c.MaxErrors {
+				result.Errors = append(result.Errors, &ImportError{Line: row.line, Error: err.Error()})
+			}
+			return nil
+		}
+		// 存在错误行且需要整体回滚时，不再写入
+		if result.Failed > 0 && !c.SkipInvalid {
+			return nil
+		}
+		batch = append(batch, data)
+		if len(batch) >= c.BatchSize {
+			return flush()
+		}
+		return nil
+	}
+
+	var readErr error
+	if format == FormatCSV {
+		readErr = readCSV(ctx.Request.Body, m, handle)
+	} else {
+		readErr = readNDJSON(ctx.Request.Body, handle)
+	}
+	if readErr == nil && (result.Failed == 0 || c.SkipInvalid) {
+		readErr = flush()
+	}
+	// 校验均在读取阶段完成
+	clearTx()
+	if dbErr != nil {
+		query.Rollback()
+		return dbErr
+	}
+	if readErr != nil {
+		query.Rollback()
+		return response.CodeBadRequest.Wrap(readErr)
+	}
+
+	if result.Failed > 0 && !c.SkipInvalid {
+		query.Rollback()
+		result.Created = 0
+		e := response.CodeValidationFailed.New(fmt.Sprintf("import failed, %d invalid rows", result.Failed))
+		e.Data = result
+		return e
+	}
+	if dryRun {
+		query.Rollback()
+		return &response.Response{
+			Msg:    "",
+			Status: 0,
+			Data:   result,
+		}
+	}
+	if result := query.Commit(); result.Error != nil {
+		return restful.NewDBResultError(result)
+	}
+	for _, e := range events {
+		notifyEvent(c.instance, e)
+	}
+
+	// after处理
+	after, ok := c.instance.(IImportAfter)
+	if ok {
+		err := after.ImportAfter(ctx, result)
+		if err != nil {
+			return response.CodeInternal.Wrap(err)
+		}
+	}
+
+	return &response.Response{
+		Msg:    "",
+		Status: 0,
+		Data:   result,
+	}
+}
+
+// Import 批量导入
+func (c *ImportMethod) Import(ctx *gin.Context) restful.Response {
+	return c.handler(ctx)
+}
@@ -0,0 +1,187 @@
+package mixins
+
+import (
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/model"
+)
+
+type ImportCase struct {
+	ID     int64  `gorm:"column:id;primaryKey" json:"id"`
+	Name   string `gorm:"column:name" json:"name"`
+	Status int32  `gorm:"column:status" json:"status"`
+}
+
+func TestReadCSV(t *testing.T) {
+	m := model.NewModel(&ImportCase{})
+	input := "\ufeffname, status\n\"a,\"\"b\"\"\",1\n\nc,\nd,2,3\ne,4\n"
+	rows := make([]*importRow, 0)
+	err := readCSV(strings.NewReader(input), m, func(row *importRow) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("TestReadCSV fail, error=%v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("TestReadCSV rows fail, got=%d", len(rows))
+	}
+	if !reflect.DeepEqual(rows[0].query, map[string]string{"name": `a,"b"`, "status": "1"}) || rows[0].line != 2 {
+		t.Errorf("TestReadCSV row fail, got=%v line=%d", rows[0].query, rows[0].line)
+	}
+	if !reflect.DeepEqual(rows[1].query, map[string]string{"name": "c"}) || rows[1].line != 4 {
+		t.Errorf("TestReadCSV empty cell fail, got=%v line=%d", rows[1].query, rows[1].line)
+	}
+	if rows[2].err == nil || rows[2].line != 5 {
+		t.Errorf("TestReadCSV wrong number of fields fail, got=%v line=%d", rows[2].err, rows[2].line)
+	}
+	if rows[3].query["name"] != "e" || rows[3].line != 6 {
+		t.Errorf("TestReadCSV row after error fail, got=%v line=%d", rows[3].query, rows[3].line)
+	}
+
+	if err := readCSV(strings.NewReader("name,unknown\na,b\n"), m, func(*importRow) error { return nil }); err == nil {
+		t.Errorf("TestReadCSV unknown field should fail")
+	}
+}
+
+func TestReadNDJSON(t *testing.T) {
+	input := "{\"name\":\"a\"}\r\n\n  {\"name\":\"b\"}\n{\"name\":\"c\"}"
+	lines := make([]int, 0)
+	bodies := make([]string, 0)
+	err := readNDJSON(strings.NewReader(input), func(row *importRow) error {
+		lines = append(lines, row.line)
+		bodies = append(bodies, string(row.body))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("TestReadNDJSON fail, error=%v", err)
+	}
+	if !reflect.DeepEqual(lines, []int{1, 3, 4}) {
+		t.Errorf("TestReadNDJSON lines fail, got=%v", lines)
+	}
+	if !reflect.DeepEqual(bodies, []string{`{"name":"a"}`, `{"name":"b"}`, `{"name":"c"}`}) {
+		t.Errorf("TestReadNDJSON bodies fail, got=%v", bodies)
+	}
+}
+
+type importAutoModel struct {
+	ID         int64  `gorm:"column:id;primaryKey" json:"id"`
+	Name       string `gorm:"column:name" json:"name"`
+	CreateTime int64  `gorm:"column:create_time" json:"createTime" auto:"create_time"`
+	CreateUser string `gorm:"column:create_user" json:"createUser" auto:"create_user"`
+}
+
+type importCase struct {
+	*restful.Resource
+	*ImportMethod
+}
+
+func TestImportAutoData(t *testing.T) {
+	db, s := newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+		if strings.HasPrefix(query, "INSERT") {
+			return &scriptResult{RowsAffected: 2, LastInsertID: 1}
+		}
+		return nil
+	})
+	app := gin.New()
+	app.Use(func(c *gin.Context) {
+		c.Set(model.AutoUserKey, "tom")
+	})
+	root := restful.New()
+	root.RegisterResource("/items", &importCase{
+		Resource:     restful.NewResourceWithDB(db, &importAutoModel{}),
+		ImportMethod: &ImportMethod{},
+	})
+	root.Mount(app.Group("/api"))
+
+	before := time.Now().Unix()
+	w := httptest.NewRecorder()
+	body := `{"name":"a","createUser":"jerry"}` + "\n" + `{"name":"b"}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/items/_import?format=ndjson", strings.NewReader(body))
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import expect 200, got=%d %s", w.Code, w.Body.String())
+	}
+	sql := "INSERT INTO `import_auto_models` (`name`,`create_time`,`create_user`) VALUES (?,?,?),(?,?,?)"
+	vars := s.Find(sql)
+	if len(vars) != 6 {
+		t.Fatalf("import insert fail, sql=%v", s.SQL())
+	}
+	// 客户端传入的自动维护字段被忽略
+	for i, name := range []string{"a", "b"} {
+		row := vars[i*3 : i*3+3]
+		if row[0] != name || row[1].(int64) < before || row[2] != "tom" {
+			t.Errorf("import auto data fail, row=%v", row)
+		}
+	}
+}
+
+func TestImportDBError(t *testing.T) {
+	cases := []struct {
+		// failOn 执行失败的SQL前缀
+		failOn string
+		err    error
+		code   int
+		body   string
+	}{
+		{"INSERT", errors.New("UNIQUE constraint failed: import_cases.name"), http.StatusConflict, `"code":"conflict"`},
+		{"COMMIT", errors.New("database is locked"), http.StatusServiceUnavailable, `"retryable":true`},
+	}
+	for _, c := range cases {
+		db, s := newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+			if strings.HasPrefix(query, c.failOn) {
+				return &scriptResult{Err: c.err}
+			}
+			return &scriptResult{RowsAffected: 1, LastInsertID: 1}
+		})
+		app := gin.New()
+		root := restful.New()
+		root.RegisterResource("/items", &importCase{
+			Resource:     restful.NewResourceWithDB(db, &ImportCase{}),
+			ImportMethod: &ImportMethod{},
+		})
+		root.Mount(app.Group("/api"))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/items/_import?format=ndjson", strings.NewReader(`{"name":"a"}`))
+		app.ServeHTTP(w, req)
+		if w.Code != c.code || !strings.Contains(w.Body.String(), c.body) || strings.Contains(w.Body.String(), c.err.Error()) {
+			t.Errorf("import %s fail expect %d, got=%d %s sql=%v", c.failOn, c.code, w.Code, w.Body.String(), s.SQL())
+		}
+	}
+}
+
+func TestImportInvalidRows(t *testing.T) {
+	db, s := newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+		return nil
+	})
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/items", &importCase{
+		Resource:     restful.NewResourceWithDB(db, &ImportCase{}),
+		ImportMethod: &ImportMethod{},
+	})
+	root.Mount(app.Group("/api"))
+
+	w := httptest.NewRecorder()
+	body := `{"name":"a"}` + "\n" + `{"status":"x"}` + "\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/items/_import?format=ndjson", strings.NewReader(body))
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":"validation_failed"`) ||
+		!strings.Contains(w.Body.String(), `"failed":1`) {
+		t.Errorf("import invalid rows expect validation_failed, got=%d %s", w.Code, w.Body.String())
+	}
+	if sql := s.SQL(); sql[len(sql)-1] != "ROLLBACK" {
+		t.Errorf("import invalid rows should rollback, sql=%v", sql)
+	}
+}
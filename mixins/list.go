@@ -1,7 +1,6 @@
 package mixins
 
 import (
-	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/lookupearth/restful/field"
 	"strings"
@@ -36,12 +35,21 @@ type ListMethod struct {
 	Limit        int
 	OrderBy      []string
 	SearchFields []string
+	// SearchBackend 检索后端，默认 LikeBackend
+	SearchBackend SearchBackend
+	// SearchRank 检索且未指定 orderBy 时按相关度排序
+	SearchRank bool
+	// FilterFields 允许的过滤字段及操作符，key 为json字段，未设置的字段使用 model 中的 filter tag
+	FilterFields map[string][]string
 
 	SearchParams interface{}
-	Decorators   []restful.HandlerDecorator
+	// OutputModel 输出模型，未设置时使用 Resource 的输出模型
+	OutputModel interface{}
+	Decorators  []restful.HandlerDecorator
 
 	ListModel   *model.Model
 	SearchModel *model.Model
+	output      *model.Output
 	handler     restful.HandlerFunc
 	instance    interface{}
 }
@@ -49,24 +57,39 @@ type ListMethod struct {
 func (c *ListMethod) InitList(resource restful.IResource) {
 	c.instance = resource
 	c.handler = restful.InstallDecorators(c.list, c.Decorators)
-	c.ListModel = model.NewModel(&ListParams{})
+	c.ListModel = model.NewParamsWithDB(resource.GetDB(), &ListParams{})
 	if c.SearchParams != nil {
-		c.SearchModel = model.NewModel(c.SearchParams)
+		c.SearchModel = model.NewParamsWithDB(resource.GetDB(), c.SearchParams)
 	}
+	if c.OutputModel != nil {
+		c.output = model.NewOutput(c.OutputModel)
+	}
+}
+
+func (c *ListMethod) searchBackend() SearchBackend {
+	if c.SearchBackend == nil {
+		return LikeBackend{}
+	}
+	return c.SearchBackend
 }
 
+// SearchQuery 生成检索条件，检索词之间为 AND 关系，双引号包裹的内容作为短语
+//
+//	query 为不带条件的查询句柄（如 resource.Query()），返回值作为条件通过 query.Where 添加，无检索时返回nil
 func (c *ListMethod) SearchQuery(query *gorm.DB, search string) *gorm.DB {
-	search = strings.TrimSpace(search)
-	if len(c.SearchFields) > 0 && len(search) > 0 {
-		terms := strings.Split(search, " ")
-		for _, term := range terms {
-			for _, field := range c.SearchFields {
-				query = query.Or(fmt.Sprintf("`%s` LIKE ?", field), "%"+term+"%")
-			}
-		}
+	terms := ParseSearchTerms(search)
+	if len(c.SearchFields) == 0 || len(terms) == 0 {
+		return nil
+	}
+	return c.searchBackend().Where(query, c.SearchFields, terms)
+}
+
+// withSearch 添加 SearchQuery 返回的检索条件
+func withSearch(query *gorm.DB, search *gorm.DB) *gorm.DB {
+	if search == nil {
 		return query
 	}
-	return nil
+	return query.Where(search)
 }
 
 func (c *ListMethod) ParseOrderBy(orderStr string) []string {
@@ -81,6 +104,22 @@ func (c *ListMethod) ParseOrderBy(orderStr string) []string {
 	return ret
 }
 
+// OrderQuery 添加排序，orderBy 为空时使用默认排序，开启 SearchRank 时优先按相关度排序
+func (c *ListMethod) OrderQuery(query *gorm.DB, orderBy string, search string) *gorm.DB {
+	orders := c.OrderBy
+	if len(orderBy) > 0 {
+		orders = c.ParseOrderBy(orderBy)
+	} else if c.SearchRank && len(c.SearchFields) > 0 {
+		if terms := ParseSearchTerms(search); len(terms) > 0 {
+			query = c.searchBackend().Order(query, c.SearchFields, terms)
+		}
+	}
+	for _, order := range orders {
+		query = query.Order(order)
+	}
+	return query
+}
+
 func (c *ListMethod) Paginate(query *gorm.DB, listData *ListParams) *gorm.DB {
 	page := int(listData.Page)
 	size := int(listData.Size)
@@ -112,6 +151,32 @@ func (c *ListMethod) Paginate(query *gorm.DB, listData *ListParams) *gorm.DB {
 	return query
 }
 
+// FilterQuery 添加 SearchParams 条件与 field__op=value 形式的过滤条件
+func (c *ListMethod) FilterQuery(ctx *gin.Context, query *gorm.DB, params map[string]string) (*gorm.DB, *response.Error) {
+	resource := restful.ResourceFromContext(ctx)
+	if c.SearchModel != nil {
+		searchSerializer := resource.GetSerializer(c.SearchModel)
+		if err := searchSerializer.ParseFromQuery(ctx, params); err != nil {
+			return nil, response.NewError(400, err)
+		}
+		if err := searchSerializer.Validate(ctx); err != nil {
+			return nil, err
+		}
+		searchData := searchSerializer.JsonData()
+		for key, value := range searchData {
+			query = c.SearchModel.Where(query, key, value)
+		}
+	}
+	conditions, err := resource.GetModel().ParseFilters(params, c.FilterFields)
+	if err != nil {
+		return nil, response.NewError(400, err)
+	}
+	for _, condition := range conditions {
+		query = query.Where(condition.Expression())
+	}
+	return query, nil
+}
+
 // List 查询数据列表，遵循 Restful 查询规范
 func (c *ListMethod) list(ctx *gin.Context) restful.Response {
 	resource := restful.ResourceFromContext(ctx)
@@ -128,18 +193,9 @@ func (c *ListMethod) list(ctx *gin.Context) restful.Response {
 	// GET 请求参数校验+提取
 	m := resource.GetModel()
 	query := resource.QueryWithContext(ctx)
-	if c.SearchModel != nil {
-		searchSerializer := resource.GetSerializer(c.SearchModel)
-		if err := searchSerializer.ParseFromQuery(ctx, params); err != nil {
-			return response.NewError(400, err)
-		}
-		if err := searchSerializer.Validate(ctx); err != nil {
-			return err
-		}
-		searchData := searchSerializer.JsonData()
-		for key, value := range searchData {
-			query = c.SearchModel.Where(query, key, value)
-		}
+	query, filterErr := c.FilterQuery(ctx, query, params)
+	if filterErr != nil {
+		return filterErr
 	}
 	listSerializer := resource.GetSerializer(c.ListModel)
 	if err := listSerializer.ParseFromQuery(ctx, params); err != nil {
@@ -149,25 +205,14 @@ func (c *ListMethod) list(ctx *gin.Context) restful.Response {
 		return err
 	}
 	listData := listSerializer.StructData().(*ListParams)
-	// like检索逻辑，实际使用注意性能
-	subQuery := c.SearchQuery(resource.Query(), string(listData.Search))
-	if subQuery != nil {
-		query = query.Where(subQuery)
-	}
+	// 检索
+	query = withSearch(query, c.SearchQuery(resource.Query(), string(listData.Search)))
 	// 获取数量
 	var total int64
 	query.Count(&total)
 
 	// 排序
-	orders := c.OrderBy
-	if len(listData.OrderBy) > 0 {
-		orders = c.ParseOrderBy(string(listData.OrderBy))
-	}
-	if len(orders) > 0 {
-		for _, order := range orders {
-			query = query.Order(order)
-		}
-	}
+	query = c.OrderQuery(query, string(listData.OrderBy), string(listData.Search))
 	// 分页
 	query = c.Paginate(query, listData)
 
@@ -186,6 +231,11 @@ func (c *ListMethod) list(ctx *gin.Context) restful.Response {
 			return response.NewError(500, err)
 		}
 	}
+	// 虚拟字段、输出模型、稀疏字段
+	results, err := outputData(ctx, m, outputModel(ctx, c.output), results)
+	if err != nil {
+		return response.NewError(500, err)
+	}
 
 	return &response.Response{
 		Msg:    "",
@@ -0,0 +1,237 @@
+package mixins
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FieldsParam 稀疏字段参数，如 ?fields=id,name，只返回指定的json字段
+const FieldsParam = "fields"
+
+// ParseFields 解析稀疏字段参数，未设置时返回nil
+func ParseFields(ctx *gin.Context) []string {
+	value, ok := ctx.GetQuery(FieldsParam)
+	if !ok {
+		return nil
+	}
+	fields := make([]string, 0)
+	for _, f := range strings.Split(value, ",") {
+		f = strings.TrimSpace(f)
+		if len(f) > 0 {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// SparseFields 按稀疏字段参数裁剪返回数据，data 为对象或对象列表
+func SparseFields(ctx *gin.Context, data interface{}) (interface{}, error) {
+	return sparseFields(data, ParseFields(ctx))
+}
+
+// sparseFields 按json字段裁剪数据，fields 为nil时不裁剪
+func sparseFields(data interface{}, fields []string) (interface{}, error) {
+	if fields == nil || data == nil {
+		return data, nil
+	}
+	value, err := jsonValue(data)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return pickFields(v, fields), nil
+	case []interface{}:
+		for i, item := range v {
+			if obj, ok := item.(map[string]interface{}); ok {
+				v[i] = pickFields(obj, fields)
+			}
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// outputData 返回数据前添加虚拟字段、转换为输出模型并按稀疏字段裁剪，data 为 model 对象或列表
+//
+//	out 不为空时稀疏字段按输出模型的json字段裁剪；
+//	GetAfter/ListAfter 返回的数据已不是 model 时无法计算虚拟字段与转换，只做稀疏字段处理
+func outputData(ctx *gin.Context, m *model.Model, out *model.Output, data interface{}) (interface{}, error) {
+	return outputFields(ctx, m, out, data, ParseFields(ctx))
+}
+
+// outputFields 同 outputData，按 fields 裁剪，fields 为nil时返回全部字段
+func outputFields(ctx *gin.Context, m *model.Model, out *model.Output, data interface{}, fields []string) (interface{}, error) {
+	if (len(m.Computed) == 0 && out == nil) || data == nil {
+		return sparseFields(data, fields)
+	}
+	// 只计算请求的虚拟字段，输出模型可能重命名字段，需全部计算
+	var keys []string
+	if fields != nil && out == nil {
+		keys = make([]string, 0)
+		for _, f := range fields {
+			if _, ok := m.Computed[f]; ok {
+				keys = append(keys, f)
+			}
+		}
+	}
+	object := func(item interface{}) (interface{}, error) {
+		value, err := jsonValue(item)
+		if err != nil {
+			return nil, err
+		}
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		computed, err := m.ComputeFields(item, keys)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range computed {
+			obj[k] = v
+		}
+		if out != nil {
+			converted, err := out.Convert(obj)
+			if err != nil || fields == nil {
+				return converted, err
+			}
+			value, err := jsonValue(converted)
+			if err != nil {
+				return nil, err
+			}
+			return pickFields(value.(map[string]interface{}), fields), nil
+		}
+		if fields != nil {
+			return pickFields(obj, fields), nil
+		}
+		return obj, nil
+	}
+	if m.IsModelData(data) {
+		return object(data)
+	}
+	list := reflect.Indirect(reflect.ValueOf(data))
+	if list.Kind() != reflect.Slice {
+		return sparseFields(data, fields)
+	}
+	itemType := list.Type().Elem()
+	if itemType.Kind() == reflect.Ptr {
+		itemType = itemType.Elem()
+	}
+	if itemType != m.ModelType {
+		return sparseFields(data, fields)
+	}
+	items := make([]interface{}, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		if item.Kind() != reflect.Ptr {
+			item = item.Addr()
+		}
+		obj, err := object(item.Interface())
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, obj)
+	}
+	return items, nil
+}
+
+// jsonValue 将数据转换为json对应的 map/slice 结构
+func jsonValue(data interface{}) (interface{}, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	// 避免大整数精度丢失
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func pickFields(obj map[string]interface{}, fields []string) map[string]interface{} {
+	picked := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if v, ok := obj[f]; ok {
+			picked[f] = v
+		}
+	}
+	return picked
+}
+
+// reload 在写事务内按主键重新读取数据，用于返回更新后的对象
+func reload(query *gorm.DB, m *model.Model) interface{} {
+	data := m.New()
+	result := query.First(data)
+	restful.CheckDBResult(result)
+	return data
+}
+
+// lockForUpdate 读取时加行锁，sqlite 不支持 FOR UPDATE
+func lockForUpdate(query *gorm.DB) *gorm.DB {
+	if query.Dialector.Name() == "sqlite" {
+		return query
+	}
+	return query.Clauses(clause.Locking{Strength: "UPDATE"})
+}
+
+// outputModel 获取输出模型，方法设置的 OutputModel 优先于 Resource 设置
+func outputModel(ctx *gin.Context, out *model.Output) *model.Output {
+	if out != nil {
+		return out
+	}
+	return restful.ResourceOutput(restful.ResourceFromContext(ctx), ctx)
+}
+
+// outputGetter 嵌入了 GetMethod 的资源
+type outputGetter interface {
+	getOutput() *model.Output
+}
+
+// outputObject 返回单个对象前的统一处理：GetAfter 加工 + 虚拟字段 + 输出模型 + 稀疏字段
+//
+//	输出模型与 GET 一致，GetMethod.OutputModel 优先于 Resource 设置
+func outputObject(ctx *gin.Context, instance interface{}, data interface{}) (interface{}, error) {
+	after, ok := instance.(IGetAfter)
+	if ok {
+		var err error
+		data, err = after.GetAfter(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var out *model.Output
+	if getter, ok := instance.(outputGetter); ok {
+		out = getter.getOutput()
+	}
+	resource := restful.ResourceFromContext(ctx)
+	return outputData(ctx, resource.GetModel(), outputModel(ctx, out), data)
+}
+
+// preferMinimal 请求头 Prefer: return=minimal 时不返回数据
+func preferMinimal(ctx *gin.Context) bool {
+	for _, prefer := range ctx.Request.Header.Values("Prefer") {
+		for _, p := range strings.Split(prefer, ",") {
+			if strings.TrimSpace(p) == "return=minimal" {
+				ctx.Header("Preference-Applied", "return=minimal")
+				return true
+			}
+		}
+	}
+	return false
+}
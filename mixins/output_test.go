@@ -0,0 +1,251 @@
+package mixins
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/model"
+)
+
+type outputCase struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+}
+
+func TestSparseFields(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/?fields=id,%20name,unknown", nil)
+
+	data, err := SparseFields(ctx, &outputCase{ID: 9007199254740993, Name: "a", Desc: "b"})
+	if err != nil {
+		t.Fatalf("SparseFields fail, error=%v", err)
+	}
+	b, _ := json.Marshal(data)
+	if string(b) != `{"id":9007199254740993,"name":"a"}` {
+		t.Errorf("SparseFields object fail, got=%s", b)
+	}
+
+	list, _ := SparseFields(ctx, &[]outputCase{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}})
+	b, _ = json.Marshal(list)
+	if string(b) != `[{"id":1,"name":"a"},{"id":2,"name":"b"}]` {
+		t.Errorf("SparseFields list fail, got=%s", b)
+	}
+
+	ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	raw := &outputCase{ID: 1}
+	if data, _ := SparseFields(ctx, raw); data != raw {
+		t.Errorf("SparseFields without fields should return data as is")
+	}
+}
+
+func TestPreferMinimal(t *testing.T) {
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+	if preferMinimal(ctx) {
+		t.Errorf("preferMinimal without header fail")
+	}
+	ctx.Request.Header.Set("Prefer", "handling=strict, return=minimal")
+	if !preferMinimal(ctx) || w.Header().Get("Preference-Applied") != "return=minimal" {
+		t.Errorf("preferMinimal fail")
+	}
+}
+
+type computedCase struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+func (c *computedCase) ComputedFields() map[string]string {
+	return map[string]string{"fullName": "FullName"}
+}
+
+func (c computedCase) FullName() string {
+	return c.FirstName + " " + c.LastName
+}
+
+func TestOutputData(t *testing.T) {
+	m := model.NewModel(&computedCase{})
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	data, err := outputData(ctx, m, nil, &computedCase{ID: 1, FirstName: "a", LastName: "b"})
+	if err != nil {
+		t.Fatalf("outputData fail, error=%v", err)
+	}
+	b, _ := json.Marshal(data)
+	if string(b) != `{"firstName":"a","fullName":"a b","id":1,"lastName":"b"}` {
+		t.Errorf("outputData object fail, got=%s", b)
+	}
+
+	ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/?fields=id,fullName", nil)
+	list, err := outputData(ctx, m, nil, &[]computedCase{{ID: 1, FirstName: "a"}, {ID: 2, LastName: "b"}})
+	if err != nil {
+		t.Fatalf("outputData fail, error=%v", err)
+	}
+	b, _ = json.Marshal(list)
+	if string(b) != `[{"fullName":"a ","id":1},{"fullName":" b","id":2}]` {
+		t.Errorf("outputData list fail, got=%s", b)
+	}
+
+	// GetAfter 已转换为其他结构时只做稀疏字段处理
+	other, _ := outputData(ctx, m, nil, map[string]interface{}{"id": 1, "x": 2})
+	b, _ = json.Marshal(other)
+	if string(b) != `{"id":1}` {
+		t.Errorf("outputData other fail, got=%s", b)
+	}
+}
+
+type computedOutput struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name" from:"fullName"`
+	First string `json:"first" from:"firstName"`
+}
+
+func TestOutputDataWithOutput(t *testing.T) {
+	m := model.NewModel(&computedCase{})
+	out := model.NewOutput(&computedOutput{})
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	data, err := outputData(ctx, m, out, &computedCase{ID: 1, FirstName: "a", LastName: "b"})
+	if err != nil {
+		t.Fatalf("outputData fail, error=%v", err)
+	}
+	if _, ok := data.(*computedOutput); !ok {
+		t.Fatalf("outputData should return output model, got=%T", data)
+	}
+	b, _ := json.Marshal(data)
+	if string(b) != `{"id":1,"name":"a b","first":"a"}` {
+		t.Errorf("outputData object fail, got=%s", b)
+	}
+
+	// 稀疏字段按输出模型的json字段裁剪
+	ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/?fields=name", nil)
+	list, err := outputData(ctx, m, out, &[]computedCase{{ID: 1, FirstName: "a"}, {ID: 2, LastName: "b"}})
+	if err != nil {
+		t.Fatalf("outputData fail, error=%v", err)
+	}
+	b, _ = json.Marshal(list)
+	if string(b) != `[{"name":"a "},{"name":" b"}]` {
+		t.Errorf("outputData list fail, got=%s", b)
+	}
+}
+
+type reloadModel struct {
+	ID         int64  `gorm:"column:id;primaryKey" json:"id"`
+	Name       string `gorm:"column:name" json:"name"`
+	UpdateTime string `gorm:"column:update_time" json:"update_time,readonly"`
+}
+
+type reloadCase struct {
+	*restful.Resource
+	*PutMethod
+	*PatchMethod
+}
+
+func (r *reloadCase) GetAfter(ctx *gin.Context, data interface{}) (interface{}, error) {
+	data.(*reloadModel).Name += "!"
+	return data, nil
+}
+
+func TestReload(t *testing.T) {
+	db, s := newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+		switch {
+		case strings.HasPrefix(query, "UPDATE"):
+			return &scriptResult{RowsAffected: 1}
+		case strings.HasPrefix(query, "SELECT"):
+			return &scriptResult{
+				Columns: []string{"id", "name", "update_time"},
+				Rows:    [][]driver.Value{{int64(7), "b", "2024-01-02 03:04:05"}},
+			}
+		}
+		return nil
+	})
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/items", &reloadCase{
+		Resource:    restful.NewResourceWithDB(db, &reloadModel{}),
+		PutMethod:   &PutMethod{},
+		PatchMethod: &PatchMethod{},
+	})
+	root.Mount(app.Group("/api"))
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/items/7?fields=name,update_time", strings.NewReader(`{"name":"b"}`))
+		req.Header.Set("Content-Type", "application/json")
+		app.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"data":{"name":"b!","update_time":"2024-01-02 03:04:05"}`) {
+			t.Errorf("%s reload fail, got=%d %s", method, w.Code, w.Body.String())
+		}
+		// 在同一事务内提交前读取
+		sql := s.SQL()
+		if len(sql) < 3 || !strings.HasPrefix(sql[len(sql)-2], "SELECT") || sql[len(sql)-1] != "COMMIT" {
+			t.Errorf("%s reload should read in transaction, sql=%v", method, sql)
+		}
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(method, "/api/items/7", strings.NewReader(`{"name":"b"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Prefer", "return=minimal")
+		app.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), `"data"`) {
+			t.Errorf("%s return=minimal fail, got=%d %s", method, w.Code, w.Body.String())
+		}
+	}
+}
+
+type reloadOutput struct {
+	Title string `json:"title" from:"name"`
+}
+
+type reloadGetCase struct {
+	*restful.Resource
+	*GetMethod
+	*PutMethod
+	*PatchMethod
+}
+
+func TestWriteOutputModel(t *testing.T) {
+	db, _ := newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+		switch {
+		case strings.HasPrefix(query, "UPDATE"):
+			return &scriptResult{RowsAffected: 1}
+		case strings.HasPrefix(query, "SELECT"):
+			return &scriptResult{Columns: []string{"id", "name"}, Rows: [][]driver.Value{{int64(7), "b"}}}
+		}
+		return nil
+	})
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/items", &reloadGetCase{
+		Resource:    restful.NewResourceWithDB(db, &reloadModel{}),
+		GetMethod:   &GetMethod{OutputModel: &reloadOutput{}},
+		PutMethod:   &PutMethod{},
+		PatchMethod: &PatchMethod{},
+	})
+	root.Mount(app.Group("/api"))
+
+	for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodPatch} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/items/7", strings.NewReader(`{"name":"b"}`))
+		req.Header.Set("Content-Type", "application/json")
+		app.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"data":{"title":"b"}`) {
+			t.Errorf("%s output model fail, got=%d %s", method, w.Code, w.Body.String())
+		}
+	}
+}
@@ -1,9 +1,15 @@
 package mixins
 
 import (
+	"encoding/json"
+	"errors"
+
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
+	"github.com/lookupearth/restful/jsonpatch"
 	"github.com/lookupearth/restful/response"
 )
 
@@ -31,6 +37,9 @@ func (c *PatchMethod) InitPatch(resource restful.IResource) {
 // Patch 部分更新
 //
 //	仅更新用户提交的字段（遵循GORM结构体中读写限制的字段规则）
+//	Content-Type 为 application/json-patch+json (RFC 6902) 或 application/merge-patch+json (RFC 7396) 时，
+//	在当前数据上应用 patch（含 field.JSONObject/field.JSON 内部字段），仅更新发生变化的字段
+//	返回更新后的数据（经过 GetAfter 处理），请求头 Prefer: return=minimal 时不返回
 func (c *PatchMethod) patch(ctx *gin.Context) restful.Response {
 	resource := restful.ResourceFromContext(ctx)
 
@@ -45,21 +54,52 @@ func (c *PatchMethod) patch(ctx *gin.Context) restful.Response {
 
 	model := resource.GetModel()
 	serializer := resource.GetPartialSerializer(model).WithDefaults(c.WithDefaults)
+	// GORM 实例化
+	query := resource.QueryPrimaryKey(ctx)
+	query = query.Begin()
+	// 事务内的校验（如 unique）使用同一事务查询
+	clearTx := restful.ContextWithTx(ctx, query)
+	defer func() {
+		if r := recover(); r != nil {
+			clearTx()
+			query.Rollback()
+			panic(r)
+		}
+	}()
 
-	if err := serializer.ParseFromBody(ctx); err != nil {
-		return response.NewError(400, err)
-	}
-	if err := serializer.Validate(ctx); err != nil {
+	if err := c.parse(ctx, query, serializer); err != nil {
+		clearTx()
+		query.Rollback()
 		return err
 	}
+	validateErr := serializer.Validate(ctx)
+	clearTx()
+	if validateErr != nil {
+		query.Rollback()
+		return validateErr
+	}
 	updateData := serializer.ValidateData()
 
-	// GORM 实例化
-	query := resource.QueryPrimaryKey(ctx)
-
+	pk := eventPrimaryKey(ctx, query, resource)
 	// DB Update 操作
 	result := query.Updates(updateData)
 	restful.CheckDBResult(result)
+	// 同一事务内读取更新后的数据
+	var data interface{}
+	minimal := preferMinimal(ctx)
+	if !minimal {
+		data = reload(query, model)
+	}
+	// 事件与数据在同一事务内写入
+	e, err := emitEvent(c.instance, query, event.ActionUpdate, pk, model, updateData)
+	if err != nil {
+		query.Rollback()
+		return response.NewError(500, err)
+	}
+	if result := query.Commit(); result.Error != nil {
+		return restful.NewDBResultError(result)
+	}
+	notifyEvent(c.instance, e)
 
 	// after处理
 	after, ok := c.instance.(IPatchAfter)
@@ -71,10 +111,90 @@ func (c *PatchMethod) patch(ctx *gin.Context) restful.Response {
 		}
 	}
 
-	return &response.Response{
+	res := &response.Response{
 		Msg:    "",
 		Status: 0,
 	}
+	if !minimal {
+		res.Data, err = outputObject(ctx, c.instance, data)
+		if err != nil {
+			return response.NewError(500, err)
+		}
+	}
+	return res
+}
+
+// parse 按 Content-Type 解析请求体
+func (c *PatchMethod) parse(ctx *gin.Context, query *gorm.DB, serializer restful.ISerializer) *response.Error {
+	contentType := ctx.ContentType()
+	if contentType != jsonpatch.ContentTypeJSONPatch && contentType != jsonpatch.ContentTypeMergePatch {
+		if err := serializer.ParseFromBody(ctx); err != nil {
+			return response.NewError(400, err)
+		}
+		return nil
+	}
+	body := restful.RequestBodyFromContext(ctx).Get()
+	if body == nil {
+		return response.NewError(500, errors.New("body is nil"))
+	}
+	// 读取当前数据并加锁，避免并发 patch 相互覆盖
+	resource := restful.ResourceFromContext(ctx)
+	current := reload(lockForUpdate(query), resource.GetModel())
+	b, err := json.Marshal(current)
+	if err != nil {
+		return response.NewError(500, err)
+	}
+	doc, err := jsonpatch.Decode(b)
+	if err != nil {
+		return response.NewError(500, err)
+	}
+
+	var patched interface{}
+	if contentType == jsonpatch.ContentTypeMergePatch {
+		patch, err := jsonpatch.Decode(body)
+		if err != nil {
+			return response.NewError(400, err)
+		}
+		patched = jsonpatch.MergePatch(doc, patch)
+	} else {
+		ops, err := jsonpatch.ParsePatch(body)
+		if err != nil {
+			return response.NewError(400, err)
+		}
+		patched, err = jsonpatch.Apply(doc, ops)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return response.NewError(409, err)
+		} else if errors.Is(err, jsonpatch.ErrInvalidPatch) {
+			return response.NewError(400, err)
+		} else if err != nil {
+			return response.NewError(422, err)
+		}
+	}
+	patchedObj, ok := patched.(map[string]interface{})
+	if !ok {
+		return response.NewErrorFromMsg(422, "patch result must be an object")
+	}
+
+	// 仅提交发生变化的顶层字段，被删除的字段置为 null
+	changed := make(map[string]interface{})
+	for k, v := range patchedObj {
+		if old, ok := doc.(map[string]interface{})[k]; !ok || !jsonpatch.Equal(old, v) {
+			changed[k] = v
+		}
+	}
+	for k := range doc.(map[string]interface{}) {
+		if _, ok := patchedObj[k]; !ok {
+			changed[k] = nil
+		}
+	}
+	b, err = json.Marshal(changed)
+	if err != nil {
+		return response.NewError(500, err)
+	}
+	if err := serializer.Parse(ctx, b); err != nil {
+		return response.NewError(400, err)
+	}
+	return nil
 }
 
 // Patch 部分更新
@@ -0,0 +1,112 @@
+package mixins
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/field"
+	"github.com/lookupearth/restful/jsonpatch"
+)
+
+type patchModel struct {
+	ID   int64            `gorm:"column:id;primaryKey" json:"id"`
+	Name string           `gorm:"column:name" json:"name"`
+	Meta field.JSONObject `gorm:"column:meta" json:"meta"`
+}
+
+type patchCase struct {
+	*restful.Resource
+	*PatchMethod
+}
+
+func TestPatchContentType(t *testing.T) {
+	db, s := newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+		switch {
+		case strings.HasPrefix(query, "UPDATE"):
+			return &scriptResult{RowsAffected: 1}
+		case strings.HasPrefix(query, "SELECT"):
+			return &scriptResult{
+				Columns: []string{"id", "name", "meta"},
+				Rows:    [][]driver.Value{{int64(7), "a", `{"a":1,"b":{"c":2}}`}},
+			}
+		}
+		return nil
+	})
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/items", &patchCase{
+		Resource:    restful.NewResourceWithDB(db, &patchModel{}),
+		PatchMethod: &PatchMethod{},
+	})
+	root.Mount(app.Group("/api"))
+
+	cases := []struct {
+		contentType string
+		body        string
+		code        int
+		// update 执行的 UPDATE 语句及参数，为空时不应执行 UPDATE
+		update string
+		vars   string
+	}{
+		{
+			jsonpatch.ContentTypeMergePatch,
+			`{"name":"x","meta":{"b":null}}`,
+			http.StatusOK,
+			"UPDATE `patch_models` SET `meta`=?,`name`=? WHERE `patch_models`.`id` = ?",
+			`[{"a":1} x 7]`,
+		},
+		{
+			jsonpatch.ContentTypeJSONPatch,
+			`[{"op":"replace","path":"/meta/b/c","value":3}]`,
+			http.StatusOK,
+			"UPDATE `patch_models` SET `meta`=? WHERE `patch_models`.`id` = ?",
+			`[{"a":1,"b":{"c":3}} 7]`,
+		},
+		{
+			jsonpatch.ContentTypeJSONPatch,
+			`[{"op":"test","path":"/name","value":"b"}]`,
+			http.StatusConflict,
+			"",
+			"",
+		},
+		{
+			jsonpatch.ContentTypeJSONPatch,
+			`{"op":"add"}`,
+			http.StatusBadRequest,
+			"",
+			"",
+		},
+	}
+	for _, c := range cases {
+		before := len(s.SQL())
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/api/items/7", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		app.ServeHTTP(w, req)
+		if w.Code != c.code {
+			t.Errorf("%s %s expect %d, got=%d %s", c.contentType, c.body, c.code, w.Code, w.Body.String())
+			continue
+		}
+		// 先加锁读取当前数据
+		sql, vars := s.SQL()[before:], s.Vars()[before:]
+		if len(sql) < 2 || !strings.HasSuffix(sql[1], "FOR UPDATE") {
+			t.Errorf("%s should lock current row, sql=%v", c.contentType, sql)
+		}
+		update, updateVars := "", ""
+		for i, query := range sql {
+			if strings.HasPrefix(query, "UPDATE") {
+				update, updateVars = query, fmt.Sprint(vars[i])
+			}
+		}
+		if update != c.update || updateVars != c.vars {
+			t.Errorf("%s %s update fail, expect=%s %s got=%s %s", c.contentType, c.body, c.update, c.vars, update, updateVars)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
 	"github.com/lookupearth/restful/response"
 )
 
@@ -67,11 +68,20 @@ func (c *PostMethod) post(ctx *gin.Context) restful.Response {
 	// 获取新添加数据的ID
 	var ret map[string]interface{}
 	query.Select("last_insert_id() as id").Limit(1).Find(&ret)
-	query.Commit()
 	// DRDS环境，as未生效
 	if v, ok := ret["last_insert_id()"]; ok {
 		ret["id"], _ = model.ParsePrimaryKey(string(v.([]byte)))
 	}
+	// 事件与数据在同一事务内写入
+	e, err := emitEvent(c.instance, query, event.ActionCreate, ret["id"], model, validData)
+	if err != nil {
+		query.Rollback()
+		return response.NewError(500, err)
+	}
+	if result := query.Commit(); result.Error != nil {
+		return restful.NewDBResultError(result)
+	}
+	notifyEvent(c.instance, e)
 
 	// after处理
 	after, ok := c.instance.(IPostAfter)
@@ -1,10 +1,15 @@
 package mixins
 
 import (
+	"sort"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
 	"github.com/lookupearth/restful/response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IPutBefore interface {
@@ -17,6 +22,8 @@ type IPutAfter interface {
 
 type PutMethod struct {
 	Decorators []restful.HandlerDecorator
+	// CreateIfMissing 数据不存在时使用url中的主键创建，返回201，否则返回404
+	CreateIfMissing bool
 
 	handler  restful.HandlerFunc
 	instance interface{}
@@ -28,6 +35,8 @@ func (c *PutMethod) InitPut(resource restful.IResource) {
 }
 
 // Put 全量更新（在更新数据时，未设置字段但有默认值时，会使用默认值）
+//
+//	返回更新后的数据（经过 GetAfter 处理），请求头 Prefer: return=minimal 时不返回
 func (c *PutMethod) put(ctx *gin.Context) restful.Response {
 	resource := restful.ResourceFromContext(ctx)
 
@@ -46,16 +55,62 @@ func (c *PutMethod) put(ctx *gin.Context) restful.Response {
 	if err := serializer.ParseFromBody(ctx); err != nil {
 		return response.NewError(400, err)
 	}
-	if err := serializer.Validate(ctx); err != nil {
-		return err
-	}
-	updateData := serializer.ValidateData()
 
 	// GORM 实例化
 	query := resource.QueryPrimaryKey(ctx)
+	query = query.Begin()
+	// 事务内的校验（如 unique）使用同一事务查询
+	clearTx := restful.ContextWithTx(ctx, query)
+	defer func() {
+		if r := recover(); r != nil {
+			clearTx()
+			query.Rollback()
+			panic(r)
+		}
+	}()
+	validateErr := serializer.Validate(ctx)
+	clearTx()
+	if validateErr != nil {
+		query.Rollback()
+		return validateErr
+	}
+	updateData := serializer.ValidateData()
+	pk := eventPrimaryKey(ctx, query, resource)
 	// DB Update 操作
 	result := query.Updates(updateData)
 	restful.CheckDBResult(result)
+	action := event.ActionUpdate
+	// 数据未变化时 RowsAffected 也可能为0，需要确认数据是否存在
+	if result.RowsAffected == 0 && !c.exists(query) {
+		if !c.CreateIfMissing {
+			query.Rollback()
+			return response.CodeNotFound.New(gorm.ErrRecordNotFound.Error())
+		}
+		if createData, ok := c.create(ctx, query, resource, updateData); ok {
+			updateData = createData
+			action = event.ActionCreate
+			pk = eventPrimaryKey(ctx, query, resource)
+		} else {
+			// 并发创建时数据已存在，按更新处理
+			restful.CheckDBResult(query.Updates(updateData))
+		}
+	}
+	// 同一事务内读取更新后的数据
+	var data interface{}
+	minimal := preferMinimal(ctx)
+	if !minimal {
+		data = reload(query, model)
+	}
+	// 事件与数据在同一事务内写入
+	e, err := emitEvent(c.instance, query, action, pk, model, updateData)
+	if err != nil {
+		query.Rollback()
+		return response.NewError(500, err)
+	}
+	if result := query.Commit(); result.Error != nil {
+		return restful.NewDBResultError(result)
+	}
+	notifyEvent(c.instance, e)
 
 	// after处理
 	after, ok := c.instance.(IPutAfter)
@@ -67,10 +122,59 @@ func (c *PutMethod) put(ctx *gin.Context) restful.Response {
 	}
 
 	// 构造返回结果
-	return &response.Response{
+	res := &response.Response{
 		Msg:    "",
 		Status: 0,
 	}
+	if !minimal {
+		res.Data, err = outputObject(ctx, c.instance, data)
+		if err != nil {
+			return response.NewError(500, err)
+		}
+	}
+	if action == event.ActionCreate {
+		res.HTTPStatus = 201
+	}
+	return res
+}
+
+func (c *PutMethod) exists(query *gorm.DB) bool {
+	var count int64
+	result := query.Count(&count)
+	restful.CheckDBResult(result)
+	return count > 0
+}
+
+// create 使用url中的主键（或 LookupField）创建数据，数据已被并发创建时不写入并返回false
+func (c *PutMethod) create(ctx *gin.Context, query *gorm.DB, resource restful.IResource, updateData map[string]interface{}) (map[string]interface{}, bool) {
+	model := resource.GetModel()
+	lookup := restful.ResourceLookup(resource, ctx)
+	createData := make(map[string]interface{}, len(updateData)+len(lookup))
+	for column, value := range updateData {
+		createData[column] = value
+	}
+	// 创建时补充 create_time 等自动维护的字段
+	for column, value := range model.AutoData(ctx, true) {
+		if _, ok := createData[column]; !ok {
+			createData[column] = value
+		}
+	}
+	conflicts := make([]clause.Column, 0, len(lookup))
+	for column, value := range lookup {
+		createData[column] = value
+		conflicts = append(conflicts, clause.Column{Name: column})
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Name < conflicts[j].Name
+	})
+	// 冲突时不做处理，通过 RowsAffected 判断是否创建成功
+	onConflict := clause.OnConflict{
+		Columns:   conflicts,
+		DoNothing: true,
+	}
+	result := query.Session(&gorm.Session{NewDB: true}).Model(model.New()).Clauses(onConflict).Create(createData)
+	restful.CheckDBResult(result)
+	return createData, result.RowsAffected > 0
 }
 
 // Put 全量更新（在更新数据时，未设置字段但有默认值时，会使用默认值）
@@ -0,0 +1,135 @@
+package mixins
+
+import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/lookupearth/restful"
+)
+
+type putModel struct {
+	ID   int64  `gorm:"column:id;primaryKey" json:"id"`
+	Name string `gorm:"column:name" json:"name"`
+}
+
+type putCase struct {
+	*restful.Resource
+	*PutMethod
+}
+
+func newPutApp(db *gorm.DB, method *PutMethod) *gin.Engine {
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/items", &putCase{
+		Resource:  restful.NewResourceWithDB(db, &putModel{}),
+		PutMethod: method,
+	})
+	root.Mount(app.Group("/api"))
+	return app
+}
+
+func TestPut(t *testing.T) {
+	// inserted 为 false 时模拟数据已被并发创建
+	newDB := func(inserted bool) (*gorm.DB, *scriptDB) {
+		return newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+			switch {
+			case strings.HasPrefix(query, "SELECT count(*)"):
+				return &scriptResult{Columns: []string{"count(*)"}, Rows: [][]driver.Value{{int64(0)}}}
+			case strings.HasPrefix(query, "INSERT") && inserted:
+				return &scriptResult{RowsAffected: 1}
+			case strings.HasPrefix(query, "SELECT"):
+				return &scriptResult{Columns: []string{"id", "name"}, Rows: [][]driver.Value{{int64(7), "a"}}}
+			}
+			return nil
+		})
+	}
+	put := func(app *gin.Engine) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/api/items/7", strings.NewReader(`{"name":"a"}`))
+		req.Header.Set("Content-Type", "application/json")
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	db, s := newDB(true)
+	w := put(newPutApp(db, &PutMethod{}))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), `"code":"not_found"`) {
+		t.Errorf("put missing expect 404, got=%d %s", w.Code, w.Body.String())
+	}
+	if sql := s.SQL(); sql[len(sql)-1] != "ROLLBACK" {
+		t.Errorf("put missing should rollback, sql=%v", sql)
+	}
+
+	db, s = newDB(true)
+	w = put(newPutApp(db, &PutMethod{CreateIfMissing: true}))
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"data":{"id":7,"name":"a"}`) {
+		t.Errorf("put create expect 201, got=%d %s", w.Code, w.Body.String())
+	}
+	if vars := s.Find("INSERT INTO `put_models`"); len(vars) != 2 || vars[0] != int64(7) || vars[1] != "a" {
+		t.Errorf("put create insert fail, sql=%v vars=%v", s.SQL(), vars)
+	}
+
+	// 冲突时不写入，按更新处理并返回200
+	db, s = newDB(false)
+	w = put(newPutApp(db, &PutMethod{CreateIfMissing: true}))
+	if w.Code != http.StatusOK {
+		t.Errorf("put conflict expect 200, got=%d %s", w.Code, w.Body.String())
+	}
+	updates := 0
+	for _, query := range s.SQL() {
+		if strings.HasPrefix(query, "UPDATE") {
+			updates++
+		}
+	}
+	if updates != 2 {
+		t.Errorf("put conflict should update again, sql=%v", s.SQL())
+	}
+}
+
+type putUniqueModel struct {
+	ID   int64  `gorm:"column:id;primaryKey" json:"id"`
+	Name string `gorm:"column:name" json:"name" validate:"unique"`
+}
+
+type putUniqueCase struct {
+	*restful.Resource
+	*PutMethod
+}
+
+func TestPutValidateInTx(t *testing.T) {
+	db, s := newScriptDB(t, func(query string, args []driver.Value) *scriptResult {
+		switch {
+		case strings.HasPrefix(query, "SELECT count(*)"):
+			return &scriptResult{Columns: []string{"count(*)"}, Rows: [][]driver.Value{{int64(1)}}}
+		case strings.HasPrefix(query, "UPDATE"):
+			return &scriptResult{RowsAffected: 1}
+		}
+		return nil
+	})
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/items", &putUniqueCase{
+		Resource:  restful.NewResourceWithDB(db, &putUniqueModel{}),
+		PutMethod: &PutMethod{},
+	})
+	root.Mount(app.Group("/api"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/api/items/7", strings.NewReader(`{"name":"a"}`))
+	req.Header.Set("Content-Type", "application/json")
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"code":"validation_failed"`) {
+		t.Errorf("put duplicate expect validation error, got=%d %s", w.Code, w.Body.String())
+	}
+	// unique 校验在写事务内执行，失败后回滚
+	sql := s.SQL()
+	if len(sql) != 3 || sql[0] != "BEGIN" || !strings.HasPrefix(sql[1], "SELECT count(*)") || sql[2] != "ROLLBACK" {
+		t.Errorf("put should validate in tx, sql=%v", sql)
+	}
+}
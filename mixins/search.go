@@ -0,0 +1,220 @@
+package mixins
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lookupearth/restful/model"
+)
+
+// SearchBackend 列表检索后端，fields 为 SearchFields 中的数据库列
+//
+//	terms 为 ParseSearchTerms 解析后的检索词，多个检索词之间为 AND 关系
+type SearchBackend interface {
+	// Where 添加检索条件
+	Where(query *gorm.DB, fields []string, terms []string) *gorm.DB
+	// Order 按相关度排序，不支持时原样返回
+	Order(query *gorm.DB, fields []string, terms []string) *gorm.DB
+}
+
+// ParseSearchTerms 按空白切分检索词，双引号包裹的内容作为一个短语
+func ParseSearchTerms(search string) []string {
+	terms := make([]string, 0)
+	var builder strings.Builder
+	quoted := false
+	flush := func() {
+		term := strings.TrimSpace(builder.String())
+		if len(term) > 0 {
+			terms = append(terms, term)
+		}
+		builder.Reset()
+	}
+	for _, r := range search {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	flush()
+	return terms
+}
+
+func searchColumns(fields []string) []interface{} {
+	columns := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		columns = append(columns, clause.Column{Table: clause.CurrentTable, Name: f})
+	}
+	return columns
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// LikeBackend 使用 LIKE %term% 检索，检索词中的 % 与 _ 按字面匹配，无法使用索引，实际使用注意性能
+type LikeBackend struct{}
+
+func (b LikeBackend) Where(query *gorm.DB, fields []string, terms []string) *gorm.DB {
+	for _, term := range terms {
+		exprs := make([]clause.Expression, 0, len(fields))
+		for _, column := range searchColumns(fields) {
+			exprs = append(exprs, model.LikeExpression(column, "%"+model.EscapeLike(term)+"%"))
+		}
+		query = query.Where(clause.Or(exprs...))
+	}
+	return query
+}
+
+func (b LikeBackend) Order(query *gorm.DB, fields []string, terms []string) *gorm.DB {
+	return query
+}
+
+// MySQLBackend 使用 MATCH ... AGAINST 布尔模式检索，SearchFields 需建立 FULLTEXT 索引
+type MySQLBackend struct{}
+
+// against 生成布尔模式检索串，检索词均为必须项，短语使用双引号
+func (b MySQLBackend) against(terms []string) string {
+	items := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`+-<>()~*"@`, r) {
+				return ' '
+			}
+			return r
+		}, term)
+		words := strings.Fields(term)
+		if len(words) == 0 {
+			continue
+		}
+		if len(words) == 1 {
+			items = append(items, "+"+words[0])
+		} else {
+			items = append(items, `+"`+strings.Join(words, " ")+`"`)
+		}
+	}
+	return strings.Join(items, " ")
+}
+
+func (b MySQLBackend) match(fields []string, terms []string) clause.Expr {
+	vars := append(searchColumns(fields), b.against(terms))
+	return clause.Expr{
+		SQL:  "MATCH (" + placeholders(len(fields)) + ") AGAINST (? IN BOOLEAN MODE)",
+		Vars: vars,
+	}
+}
+
+func (b MySQLBackend) Where(query *gorm.DB, fields []string, terms []string) *gorm.DB {
+	return query.Where(b.match(fields, terms))
+}
+
+func (b MySQLBackend) Order(query *gorm.DB, fields []string, terms []string) *gorm.DB {
+	return query.Order(clause.OrderBy{Expression: clause.Expr{SQL: "? DESC", Vars: []interface{}{b.match(fields, terms)}}})
+}
+
+// PostgresBackend 使用 tsvector 检索
+//
+//	Vector 为预先生成的 tsvector 列，为空时由 SearchFields 实时拼接；Config 为分词配置，默认 simple
+type PostgresBackend struct {
+	Config string
+	Vector string
+}
+
+func (b PostgresBackend) config() string {
+	if b.Config == "" {
+		return "simple"
+	}
+	return b.Config
+}
+
+func (b PostgresBackend) vector(fields []string) clause.Expr {
+	if b.Vector != "" {
+		return clause.Expr{SQL: "?", Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: b.Vector}}}
+	}
+	parts := make([]string, 0, len(fields))
+	for range fields {
+		parts = append(parts, "coalesce(?, '')")
+	}
+	vars := append([]interface{}{b.config()}, searchColumns(fields)...)
+	return clause.Expr{SQL: "to_tsvector(?::regconfig, " + strings.Join(parts, " || ' ' || ") + ")", Vars: vars}
+}
+
+// tsquery 每个检索词/短语生成一个 phraseto_tsquery，之间为 AND 关系
+func (b PostgresBackend) tsquery(terms []string) clause.Expr {
+	parts := make([]string, 0, len(terms))
+	vars := make([]interface{}, 0, len(terms)*2)
+	for _, term := range terms {
+		parts = append(parts, "phraseto_tsquery(?::regconfig, ?)")
+		vars = append(vars, b.config(), term)
+	}
+	return clause.Expr{SQL: strings.Join(parts, " && "), Vars: vars}
+}
+
+func (b PostgresBackend) Where(query *gorm.DB, fields []string, terms []string) *gorm.DB {
+	return query.Where(clause.Expr{SQL: "? @@ (?)", Vars: []interface{}{b.vector(fields), b.tsquery(terms)}})
+}
+
+func (b PostgresBackend) Order(query *gorm.DB, fields []string, terms []string) *gorm.DB {
+	rank := clause.Expr{SQL: "ts_rank(?, ?) DESC", Vars: []interface{}{b.vector(fields), b.tsquery(terms)}}
+	return query.Order(clause.OrderBy{Expression: rank})
+}
+
+// SQLiteBackend 使用 FTS5 虚拟表检索
+//
+//	Table 为 FTS5 表名，其 rowid 与资源表的 Key 列对应（默认 rowid），
+//	fields 为 FTS5 表中的列名
+type SQLiteBackend struct {
+	Table string
+	Key   string
+}
+
+func (b SQLiteBackend) key() string {
+	if b.Key == "" {
+		return "rowid"
+	}
+	return b.Key
+}
+
+// match 生成 FTS5 检索串，每个检索词/短语作为一个字符串，之间为隐式 AND
+func (b SQLiteBackend) match(fields []string, terms []string) string {
+	items := make([]string, 0, len(terms))
+	for _, term := range terms {
+		items = append(items, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+	}
+	expr := strings.Join(items, " ")
+	if len(fields) > 0 {
+		expr = "{" + strings.Join(fields, " ") + "} : (" + expr + ")"
+	}
+	return expr
+}
+
+func (b SQLiteBackend) Where(query *gorm.DB, fields []string, terms []string) *gorm.DB {
+	return query.Where(clause.Expr{
+		SQL: "? IN (SELECT rowid FROM ? WHERE ? MATCH ?)",
+		Vars: []interface{}{
+			clause.Column{Table: clause.CurrentTable, Name: b.key()},
+			clause.Table{Name: b.Table}, clause.Table{Name: b.Table},
+			b.match(fields, terms),
+		},
+	})
+}
+
+func (b SQLiteBackend) Order(query *gorm.DB, fields []string, terms []string) *gorm.DB {
+	rank := clause.Expr{
+		SQL: "(SELECT rank FROM ? WHERE ? MATCH ? AND rowid = ?)",
+		Vars: []interface{}{
+			clause.Table{Name: b.Table}, clause.Table{Name: b.Table},
+			b.match(fields, terms),
+			clause.Column{Table: clause.CurrentTable, Name: b.key()},
+		},
+	}
+	// bm25 值越小越相关
+	return query.Order(clause.OrderBy{Expression: clause.Expr{SQL: "? ASC", Vars: []interface{}{rank}}})
+}
@@ -0,0 +1,103 @@
+package mixins
+
+import (
+	"reflect"
+	"testing"
+)
+
+type SearchCase struct {
+	ID    int64  `gorm:"column:id;primaryKey" json:"id"`
+	Title string `gorm:"column:title" json:"title"`
+	Body  string `gorm:"column:body" json:"body"`
+}
+
+func TestParseSearchTerms(t *testing.T) {
+	cases := map[string][]string{
+		"":                        {},
+		"  a  b ":                 {"a", "b"},
+		`a "hello world" b`:       {"a", "hello world", "b"},
+		`"unclosed phrase`:        {"unclosed phrase"},
+		`x"y z"`:                  {"x", "y z"},
+		"中文\t检索":                  {"中文", "检索"},
+		`"" "  " c`:               {"c"},
+		`"quoted"   "two words" `: {"quoted", "two words"},
+	}
+	for search, expect := range cases {
+		terms := ParseSearchTerms(search)
+		if !reflect.DeepEqual(terms, expect) {
+			t.Errorf("TestParseSearchTerms fail, search=%q expect=%q got=%q", search, expect, terms)
+		}
+	}
+}
+
+func TestSearchBackend(t *testing.T) {
+	db := dryRunDB(t)
+	fields := []string{"title", "body"}
+	terms := ParseSearchTerms(`go "hello world"`)
+	cases := []struct {
+		backend SearchBackend
+		sql     string
+		vars    []interface{}
+	}{
+		{
+			LikeBackend{},
+			"SELECT * FROM `search_cases` WHERE (`search_cases`.`title` LIKE ? ESCAPE '!' OR `search_cases`.`body` LIKE ? ESCAPE '!') AND (`search_cases`.`title` LIKE ? ESCAPE '!' OR `search_cases`.`body` LIKE ? ESCAPE '!')",
+			[]interface{}{"%go%", "%go%", "%hello world%", "%hello world%"},
+		},
+		{
+			MySQLBackend{},
+			"SELECT * FROM `search_cases` WHERE MATCH (`search_cases`.`title`,`search_cases`.`body`) AGAINST (? IN BOOLEAN MODE) ORDER BY MATCH (`search_cases`.`title`,`search_cases`.`body`) AGAINST (? IN BOOLEAN MODE) DESC",
+			[]interface{}{`+go +"hello world"`, `+go +"hello world"`},
+		},
+		{
+			PostgresBackend{Vector: "tsv"},
+			"SELECT * FROM `search_cases` WHERE `search_cases`.`tsv` @@ (phraseto_tsquery(?::regconfig, ?) && phraseto_tsquery(?::regconfig, ?)) ORDER BY ts_rank(`search_cases`.`tsv`, phraseto_tsquery(?::regconfig, ?) && phraseto_tsquery(?::regconfig, ?)) DESC",
+			[]interface{}{"simple", "go", "simple", "hello world", "simple", "go", "simple", "hello world"},
+		},
+		{
+			SQLiteBackend{Table: "search_fts"},
+			"SELECT * FROM `search_cases` WHERE `search_cases`.`rowid` IN (SELECT rowid FROM `search_fts` WHERE `search_fts` MATCH ?) ORDER BY (SELECT rank FROM `search_fts` WHERE `search_fts` MATCH ? AND rowid = `search_cases`.`rowid`) ASC",
+			[]interface{}{`{title body} : ("go" "hello world")`, `{title body} : ("go" "hello world")`},
+		},
+	}
+	for _, c := range cases {
+		query := db.Model(&SearchCase{})
+		query = c.backend.Where(query, fields, terms)
+		query = c.backend.Order(query, fields, terms)
+		query = query.Find(&[]SearchCase{})
+		if sql := query.Statement.SQL.String(); sql != c.sql {
+			t.Errorf("TestSearchBackend %T sql fail, expect=%s got=%s", c.backend, c.sql, sql)
+		}
+		if !reflect.DeepEqual(query.Statement.Vars, c.vars) {
+			t.Errorf("TestSearchBackend %T vars fail, expect=%v got=%v", c.backend, c.vars, query.Statement.Vars)
+		}
+	}
+
+	// LIKE 检索词中的通配符按字面匹配
+	query := (LikeBackend{}).Where(db.Model(&SearchCase{}), []string{"title"}, []string{"50%_off!"}).Find(&[]SearchCase{})
+	if !reflect.DeepEqual(query.Statement.Vars, []interface{}{"%50!%!_off!!%"}) {
+		t.Errorf("TestSearchBackend like escape fail, got=%v", query.Statement.Vars)
+	}
+
+	// 检索词中的布尔模式操作符会被忽略
+	if against := (MySQLBackend{}).against([]string{"a+b", "-c", `"*`}); against != `+"a b" +c` {
+		t.Errorf("TestSearchBackend against fail, got=%s", against)
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	db := dryRunDB(t)
+	c := &ListMethod{SearchFields: []string{"title"}}
+	// 无检索时返回nil
+	if c.SearchQuery(db, "  ") != nil || (&ListMethod{}).SearchQuery(db, "go") != nil {
+		t.Errorf("TestSearchQuery without search should return nil")
+	}
+	query := db.Model(&SearchCase{}).Where("id > ?", 1)
+	query = withSearch(query, c.SearchQuery(db, "go"))
+	query = withSearch(query, c.SearchQuery(db, ""))
+	query = query.Find(&[]SearchCase{})
+	expect := "SELECT * FROM `search_cases` WHERE id > ? AND `search_cases`.`title` LIKE ? ESCAPE '!'"
+	if sql := query.Statement.SQL.String(); sql != expect {
+		t.Errorf("TestSearchQuery sql fail, expect=%s got=%s", expect, sql)
+	}
+}
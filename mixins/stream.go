@@ -0,0 +1,152 @@
+package mixins
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
+	"github.com/lookupearth/restful/model"
+	"github.com/lookupearth/restful/response"
+)
+
+type IStreamBefore interface {
+	StreamBefore(*gin.Context, map[string]string) error
+}
+
+// StreamMethod 以 Server-Sent Events 推送资源的变更事件，路由为 GET <resource>/_stream
+//
+//	Broker 需同时加入 Resource.Sinks，写操作提交后才会推送。
+//	SearchParams 与 ListMethod 语义相同，created/updated 事件会按主键回查数据库判断是否命中过滤条件，
+//	deleted 事件无法回查，总是推送。
+//	回查为每个事件、每个带过滤条件的连接执行一次 COUNT 查询，连接数较多时建议由客户端过滤或不设置 SearchParams。
+type StreamMethod struct {
+	Broker       *event.Broker
+	SearchParams interface{}
+	// Heartbeat 心跳间隔，默认15秒
+	Heartbeat  time.Duration
+	Decorators []restful.HandlerDecorator
+
+	SearchModel *model.Model
+	handler     restful.HandlerFunc
+	instance    interface{}
+}
+
+func (c *StreamMethod) InitStream(resource restful.IResource) {
+	c.instance = resource
+	c.handler = restful.InstallDecorators(c.stream, c.Decorators)
+	if c.Broker == nil {
+		panic("StreamMethod need a Broker")
+	}
+	if c.Heartbeat == 0 {
+		c.Heartbeat = 15 * time.Second
+	}
+	if c.SearchParams != nil {
+		c.SearchModel = model.NewParamsWithDB(resource.GetDB(), c.SearchParams)
+	}
+}
+
+func (c *StreamMethod) stream(ctx *gin.Context) restful.Response {
+	resource := restful.ResourceFromContext(ctx)
+
+	params := restful.GetQuery(ctx)
+	// before处理
+	before, ok := c.instance.(IStreamBefore)
+	if ok {
+		err := before.StreamBefore(ctx, params)
+		if err != nil {
+			return response.NewError(500, err)
+		}
+	}
+	var searchData map[string]interface{}
+	if c.SearchModel != nil {
+		searchSerializer := resource.GetSerializer(c.SearchModel)
+		if err := searchSerializer.ParseFromQuery(ctx, params); err != nil {
+			return response.NewError(400, err)
+		}
+		if err := searchSerializer.Validate(ctx); err != nil {
+			return err
+		}
+		searchData = searchSerializer.JsonData()
+	}
+
+	return &streamResponse{
+		method:     c,
+		resource:   resource,
+		searchData: searchData,
+	}
+}
+
+// match 判断事件是否命中过滤条件，复用 ListMethod 的 where 生成逻辑，有过滤条件时每次调用执行一次 COUNT 查询
+func (c *StreamMethod) match(ctx *gin.Context, resource restful.IResource, searchData map[string]interface{}, e *event.Event) bool {
+	if len(searchData) == 0 || e.Action == event.ActionDelete {
+		return true
+	}
+	m := resource.GetModel()
+	query := m.WherePrimaryKey(resource.QueryWithContext(ctx), e.PrimaryKey)
+	for key, value := range searchData {
+		query = c.SearchModel.Where(query, key, value)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// Stream 推送资源变更事件
+func (c *StreamMethod) Stream(ctx *gin.Context) restful.Response {
+	return c.handler(ctx)
+}
+
+type streamResponse struct {
+	method     *StreamMethod
+	resource   restful.IResource
+	searchData map[string]interface{}
+}
+
+func (s *streamResponse) Response(ctx *gin.Context) {
+	// 先订阅再写响应头，客户端收到响应头后发生的事件都会推送
+	subscriber := s.method.Broker.Subscribe(restful.ResourceName(s.resource))
+	defer subscriber.Close()
+
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Writer.WriteHeader(200)
+	ctx.Writer.Flush()
+
+	ticker := time.NewTicker(s.method.Heartbeat)
+	defer ticker.Stop()
+	done := ctx.Request.Context().Done()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-subscriber.C:
+			if !ok {
+				return
+			}
+			if !s.method.match(ctx, s.resource, s.searchData, e) {
+				continue
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(ctx.Writer, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Topic(), data); err != nil {
+				return
+			}
+		}
+		ctx.Writer.Flush()
+	}
+}
@@ -0,0 +1,67 @@
+package mixins
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
+)
+
+type streamModel struct {
+	ID   int64  `gorm:"column:id;primaryKey" json:"id"`
+	Name string `gorm:"column:name" json:"name"`
+}
+
+type streamCase struct {
+	*restful.Resource
+	*StreamMethod
+}
+
+func TestStream(t *testing.T) {
+	broker := event.NewBroker()
+	resource := &streamCase{
+		Resource:     restful.NewResourceWithDB(nil, &streamModel{}),
+		StreamMethod: &StreamMethod{Broker: broker},
+	}
+	resource.AddSink(broker)
+
+	app := gin.New()
+	root := restful.New()
+	root.RegisterResource("/stream", resource)
+	root.Mount(app.Group("/api"))
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/stream/_stream")
+	if err != nil {
+		t.Fatalf("http.Get fail, error=%v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Content-Type fail, got=%s", resp.Header.Get("Content-Type"))
+	}
+
+	// 响应头返回前已完成订阅，此时通知的事件不会丢失
+	resource.NotifyEvent(event.NewEvent("stream_models", event.ActionCreate, 1))
+	resource.NotifyEvent(event.NewEvent("other", event.ActionCreate, 2))
+	reader := bufio.NewReader(resp.Body)
+	lines := make([]string, 0)
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream fail, error=%v", err)
+		}
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	if !strings.HasPrefix(lines[0], "id: ") || lines[1] != "event: stream_models.created" || !strings.Contains(lines[2], `"pk":1`) {
+		t.Errorf("stream content fail, got=%v", lines)
+	}
+}
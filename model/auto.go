@@ -0,0 +1,92 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// 自动维护的字段，写入时由服务端设置，忽略客户端传入的值
+const (
+	AutoCreateTime = "create_time"
+	AutoUpdateTime = "update_time"
+	AutoCreateUser = "create_user"
+	AutoUpdateUser = "update_user"
+)
+
+// AutoUserKey 从 context 中读取当前用户的key，gin.Context 中为 c.Set 设置的值，可在启动阶段修改
+var AutoUserKey = "user_id"
+
+// NewAuto 解析 auto tag，如 auto:"create_time"
+func NewAuto(field reflect.StructField) string {
+	tag := strings.ToLower(strings.TrimSpace(field.Tag.Get("auto")))
+	switch tag {
+	case "", AutoCreateTime, AutoUpdateTime, AutoCreateUser, AutoUpdateUser:
+		return tag
+	}
+	panic(fmt.Sprintf("auto <%s> is inlegal in field <%s>", tag, field.Name))
+}
+
+// IsAuto 字段是否为自动维护的字段
+func (field Field) IsAuto() bool {
+	return len(field.Auto) > 0
+}
+
+// AutoData 获取自动维护字段的值，key为db列；create 为 true 时包含创建与更新字段，否则只包含更新字段
+//
+//	context 中没有当前用户时不设置用户字段
+func (model *Model) AutoData(ctx context.Context, create bool) map[string]interface{} {
+	data := make(map[string]interface{})
+	current := time.Now()
+	var user interface{}
+	if ctx != nil {
+		user = ctx.Value(AutoUserKey)
+	}
+	for _, name := range model.Names {
+		field := model.Name2Field[name]
+		column, ok := model.Name2Column[name]
+		if !ok || !field.IsAuto() {
+			continue
+		}
+		switch field.Auto {
+		case AutoCreateTime:
+			if create {
+				data[column] = autoTime(field.FieldType, current)
+			}
+		case AutoUpdateTime:
+			data[column] = autoTime(field.FieldType, current)
+		case AutoCreateUser:
+			if create && user != nil {
+				data[column] = providerValue(field.FieldType, user)
+			}
+		case AutoUpdateUser:
+			if user != nil {
+				data[column] = providerValue(field.FieldType, user)
+			}
+		}
+	}
+	return data
+}
+
+// SetAutoData 将 AutoData 的值写入 model 结构体，value 为 model 的指针，用于以结构体写入数据库的场景
+func (model *Model) SetAutoData(value interface{}, data map[string]interface{}) {
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	for column, v := range data {
+		name, ok := model.Column2Name[column]
+		if !ok || !model.Name2Field[name].IsAuto() {
+			continue
+		}
+		makePtr(model.FieldValue(rv, name)).Set(reflect.ValueOf(v))
+	}
+}
+
+// autoTime 时间类型直接转换，整数类型为秒级时间戳
+func autoTime(t reflect.Type, current time.Time) interface{} {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(current.Unix()).Convert(t).Interface()
+	}
+	return providerValue(t, current)
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"context"
+	"testing"
+)
+
+type AutoCase struct {
+	Creator string  `gorm:"column:creator" auto:"create_user"`
+	Updater *string `gorm:"column:updater" auto:"update_user"`
+	Name    string  `gorm:"column:name"`
+}
+
+func TestAutoData(t *testing.T) {
+	m := NewModel(&AutoCase{})
+	data := m.AutoData(context.WithValue(context.Background(), AutoUserKey, "tom"), true)
+	if len(data) != 2 || data["creator"] != "tom" || data["updater"] != "tom" {
+		t.Errorf("AutoData fail, got=%v", data)
+	}
+	if data := m.AutoData(context.WithValue(context.Background(), AutoUserKey, "tom"), false); len(data) != 1 || data["updater"] != "tom" {
+		t.Errorf("AutoData update fail, got=%v", data)
+	}
+	if data := m.AutoData(context.Background(), true); len(data) != 0 {
+		t.Errorf("AutoData without user fail, got=%v", data)
+	}
+
+	obj := &AutoCase{Name: "a"}
+	m.SetAutoData(obj, map[string]interface{}{"creator": "tom", "updater": "tom", "name": "b"})
+	if obj.Creator != "tom" || obj.Updater == nil || *obj.Updater != "tom" || obj.Name != "a" {
+		t.Errorf("SetAutoData fail, got=%+v", obj)
+	}
+}
+
+func TestNewAutoIllegal(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("TestNewAutoIllegal illegal auto should panic")
+		}
+	}()
+	NewModel(struct {
+		Name string `auto:"delete_time"`
+	}{})
+}
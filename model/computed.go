@@ -0,0 +1,111 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// IComputedFields model 声明虚拟输出字段，key 为json字段，value 为方法名
+//
+//	方法无参数，返回 T 或 (T, error)，接收者可以是值或指针，如：
+//	func (u *User) ComputedFields() map[string]string { return map[string]string{"fullName": "FullName"} }
+//	func (u *User) FullName() string { return u.FirstName + " " + u.LastName }
+type IComputedFields interface {
+	ComputedFields() map[string]string
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ComputedField 虚拟字段，只用于输出，不与数据库和请求参数交互
+type ComputedField struct {
+	JsonKey string
+	Method  string
+	// Type 字段值类型，可用于生成文档
+	Type reflect.Type
+}
+
+// NewComputedFields 解析 model 的虚拟字段，方法不存在或签名错误时 panic
+func NewComputedFields(mt reflect.Type) map[string]*ComputedField {
+	declare, ok := reflect.New(mt).Interface().(IComputedFields)
+	if !ok {
+		return nil
+	}
+	ptrType := reflect.PtrTo(mt)
+	fields := make(map[string]*ComputedField)
+	for jsonKey, name := range declare.ComputedFields() {
+		method, ok := ptrType.MethodByName(name)
+		if !ok {
+			panic(fmt.Sprintf("computed field <%s> method <%s> not found in model <%s>", jsonKey, name, mt.Name()))
+		}
+		t := method.Type
+		validReturn := t.NumOut() == 1 || (t.NumOut() == 2 && t.Out(1) == errorType)
+		if t.NumIn() != 1 || !validReturn {
+			panic(fmt.Sprintf("computed field <%s> method <%s> should be func() T or func() (T, error)", jsonKey, name))
+		}
+		fields[jsonKey] = &ComputedField{
+			JsonKey: jsonKey,
+			Method:  name,
+			Type:    t.Out(0),
+		}
+	}
+	return fields
+}
+
+// Value 计算虚拟字段的值，data 为 model 指针
+func (field *ComputedField) Value(data interface{}) (interface{}, error) {
+	out := reflect.ValueOf(data).MethodByName(field.Method).Call(nil)
+	if len(out) == 2 && !out[1].IsNil() {
+		return nil, out[1].Interface().(error)
+	}
+	return out[0].Interface(), nil
+}
+
+// ComputedKeys 按名称排序的虚拟字段json key
+func (model *Model) ComputedKeys() []string {
+	keys := make([]string, 0, len(model.Computed))
+	for key := range model.Computed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// IsModelData 判断 data 是否为 model 结构体（或其指针）
+func (model *Model) IsModelData(data interface{}) bool {
+	t := reflect.TypeOf(data)
+	if t == nil {
+		return false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == model.ModelType
+}
+
+// ComputeFields 计算 data 的虚拟字段，keys 为空时计算全部，data 为 model 结构体或指针
+func (model *Model) ComputeFields(data interface{}, keys []string) (map[string]interface{}, error) {
+	value := reflect.ValueOf(data)
+	if value.Kind() != reflect.Ptr {
+		// 值类型无法调用指针接收者的方法，复制一份
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+	if keys == nil {
+		keys = model.ComputedKeys()
+	}
+	values := make(map[string]interface{})
+	for _, key := range keys {
+		field, ok := model.Computed[key]
+		if !ok {
+			continue
+		}
+		v, err := field.Value(value.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("computed field %s failed, %s", key, err.Error())
+		}
+		values[key] = v
+	}
+	return values, nil
+}
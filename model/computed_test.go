@@ -0,0 +1,95 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type ComputedCase struct {
+	ID     int64  `gorm:"column:id;primaryKey" json:"id"`
+	Name   string `gorm:"column:name" json:"name"`
+	Expire int64  `gorm:"column:expire" json:"expire"`
+}
+
+func (c *ComputedCase) ComputedFields() map[string]string {
+	return map[string]string{"title": "Title", "isExpired": "IsExpired", "check": "Check"}
+}
+
+func (c ComputedCase) Title() string {
+	return "#" + c.Name
+}
+
+func (c *ComputedCase) IsExpired() bool {
+	return c.Expire > 0
+}
+
+func (c *ComputedCase) Check() (int, error) {
+	if c.ID == 0 {
+		return 0, errors.New("empty id")
+	}
+	return int(c.ID), nil
+}
+
+type ComputedMissingCase struct {
+	ID int64 `json:"id"`
+}
+
+func (c ComputedMissingCase) ComputedFields() map[string]string {
+	return map[string]string{"x": "Missing"}
+}
+
+type ComputedConflictCase struct {
+	ID int64 `json:"id"`
+}
+
+func (c ComputedConflictCase) ComputedFields() map[string]string {
+	return map[string]string{"id": "Value"}
+}
+
+func (c ComputedConflictCase) Value() int64 {
+	return c.ID
+}
+
+func TestComputedFields(t *testing.T) {
+	m := NewModel(&ComputedCase{})
+	if !reflect.DeepEqual(m.ComputedKeys(), []string{"check", "isExpired", "title"}) {
+		t.Errorf("TestComputedFields keys fail, got=%v", m.ComputedKeys())
+	}
+	if m.Computed["isExpired"].Type.Kind() != reflect.Bool {
+		t.Errorf("TestComputedFields type fail, got=%v", m.Computed["isExpired"].Type)
+	}
+	if _, ok := m.Json2Name["title"]; ok {
+		t.Errorf("TestComputedFields should not be a model field")
+	}
+
+	values, err := m.ComputeFields(ComputedCase{ID: 1, Name: "a", Expire: 1}, nil)
+	if err != nil {
+		t.Fatalf("TestComputedFields ComputeFields fail, error=%v", err)
+	}
+	expect := map[string]interface{}{"title": "#a", "isExpired": true, "check": 1}
+	if !reflect.DeepEqual(values, expect) {
+		t.Errorf("TestComputedFields values fail, expect=%v got=%v", expect, values)
+	}
+	values, _ = m.ComputeFields(&ComputedCase{Name: "b"}, []string{"title", "name"})
+	if !reflect.DeepEqual(values, map[string]interface{}{"title": "#b"}) {
+		t.Errorf("TestComputedFields keys fail, got=%v", values)
+	}
+	if _, err := m.ComputeFields(&ComputedCase{}, []string{"check"}); err == nil {
+		t.Errorf("TestComputedFields error should return")
+	}
+
+}
+
+func TestComputedFieldsPanic(t *testing.T) {
+	for _, c := range []interface{}{&ComputedMissingCase{}, &ComputedConflictCase{}} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("TestComputedFieldsPanic %T should panic", c)
+				}
+			}()
+			NewModel(c)
+		}()
+	}
+}
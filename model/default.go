@@ -47,6 +47,18 @@ func NewDefault(field reflect.StructField) *Default {
 		fieldType:   fieldType,
 	}
 	if f.haveDefault {
+		// ProviderPrefix 开头为与类型无关的 provider，如 @uuid、@ctx:user_id、@now+24h，见 RegisterDefaultProvider
+		if providerName, providerParams, ok := splitProvider(strings.TrimSpace(tag)); ok {
+			provider, ok := defaultProviders[providerName]
+			if !ok {
+				panic(fmt.Errorf("unknown default provider %s for %s", providerName, fieldType))
+			}
+			f.processProvider(provider, providerParams)
+			if f.err != nil {
+				panic(f.err)
+			}
+			return f
+		}
 		fieldValue := reflect.New(fieldType).Elem().Interface()
 		// 优先尝试解析一下
 		if f.haveDefault {
@@ -101,6 +113,22 @@ func (d *Default) processDefaultFunc(params string, dpf DefaultPrepareFunc, df D
 	}
 }
 
+// processProvider provider 校验失败时记录错误
+func (d *Default) processProvider(provider *defaultProvider, params string) {
+	prepareValue, err := provider.prepare(d.fieldType, params)
+	if err != nil {
+		d.err = fmt.Errorf("default %s for %s, %s", d.Value, d.fieldType, err.Error())
+		return
+	}
+	fieldType := d.fieldType
+	fn := provider.fn
+	d.ValueInterface = prepareValue
+	d.DefaultFunc = func(ctx context.Context, v interface{}) interface{} {
+		return providerValue(fieldType, fn(ctx, v))
+	}
+	d.err = nil
+}
+
 func (d *Default) HaveDefault() bool {
 	return d.haveDefault
 }
@@ -0,0 +1,195 @@
+package model
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm/schema"
+)
+
+var (
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// embedPath 嵌入结构体的路径信息
+type embedPath struct {
+	// index 结构体字段下标路径
+	index []int
+	// bind 结构体字段名路径，与 GORM 的 BindNames 一致
+	bind []string
+	// name/json 字段名与json字段前缀，匿名嵌入时不变
+	name string
+	json string
+	// column 列名前缀，见 gorm embeddedPrefix
+	column string
+}
+
+func (p *embedPath) child(f reflect.StructField) *embedPath {
+	return &embedPath{
+		index:  append(append([]int{}, p.index...), f.Index...),
+		bind:   append(append([]string{}, p.bind...), f.Name),
+		name:   p.name,
+		json:   p.json,
+		column: p.column,
+	}
+}
+
+// embeddedStruct 判断字段是否按嵌入结构体展开，返回结构体类型
+//
+//	匿名结构体或带 gorm:"embedded" 的结构体展开，实现了 Scanner/Valuer 的类型（如时间）按普通字段处理
+func embeddedStruct(f reflect.StructField, tags map[string]string) (reflect.Type, bool) {
+	_, embedded := tags["EMBEDDED"]
+	if !f.Anonymous && !embedded {
+		return nil, false
+	}
+	t := f.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isTime(t) {
+		return nil, false
+	}
+	ptr := reflect.PtrTo(t)
+	if t.Implements(valuerType) || ptr.Implements(valuerType) || ptr.Implements(scannerType) {
+		return nil, false
+	}
+	return t, true
+}
+
+// initFields 递归解析结构体字段，同层的普通字段优先于嵌入结构体中的同名字段
+func (model *Model) initFields(t reflect.Type, path *embedPath) {
+	embeds := make([]reflect.StructField, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := schema.ParseTagSetting(f.Tag.Get("gorm"), ";")
+		if !f.IsExported() {
+			continue
+		}
+		if _, ok := embeddedStruct(f, tags); ok && tags["-"] != "-" {
+			embeds = append(embeds, f)
+			continue
+		}
+		model.addField(f, path.child(f))
+	}
+	for _, f := range embeds {
+		tags := schema.ParseTagSetting(f.Tag.Get("gorm"), ";")
+		et, _ := embeddedStruct(f, tags)
+		child := path.child(f)
+		child.column += tags["EMBEDDEDPREFIX"]
+		// 匿名嵌入且未指定json名时，字段提升到上一层；否则为具名嵌套对象
+		jsonKey := NewJson(f).Name
+		if !f.Anonymous || f.Tag.Get("json") != "" {
+			if jsonKey == "" {
+				continue
+			}
+			child.name += f.Name + "."
+			child.json += jsonKey
+			model.Nested[child.json] = true
+			child.json += "."
+		}
+		model.initFields(et, child)
+	}
+}
+
+func (model *Model) addField(f reflect.StructField, path *embedPath) {
+	name := path.name + f.Name
+	if _, ok := model.Name2Field[name]; ok {
+		// 与上层字段同名，按 Go 的规则被覆盖
+		return
+	}
+	field := NewField(f)
+	field.Index = path.index
+	field.Namespace = strings.Join(path.bind, ".")
+	if model.Schema != nil {
+		field.Gorm.Column = model.schemaColumn(path, field)
+	} else if field.Gorm.Column != "" {
+		if _, ok := field.Gorm.Tags["COLUMN"]; !ok && model.namer != nil {
+			field.Gorm.Column = model.namer.ColumnName("", f.Name)
+		}
+		field.Gorm.Column = path.column + field.Gorm.Column
+	}
+	field.DBKey = field.Gorm.Column
+	if field.Json.Name != "" {
+		field.Json.Name = path.json + field.Json.Name
+		field.JsonKey = field.Json.Name
+	}
+	model.Names = append(model.Names, name)
+	model.Name2Field[name] = field
+	if len(field.Json.Name) > 0 {
+		model.Name2Json[name] = field.Json.Name
+		model.Json2Name[field.Json.Name] = name
+	}
+	if len(field.Gorm.Column) > 0 {
+		model.Name2Column[name] = field.Gorm.Column
+		model.Column2Name[field.Gorm.Column] = name
+	}
+	if field.PrimaryKey && len(field.Gorm.Column) > 0 {
+		model.PrimaryKeys = append(model.PrimaryKeys, field.Gorm.Column)
+	}
+}
+
+// lessIndex 按结构体字段顺序比较下标路径
+func lessIndex(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// schemaColumn 从 Schema 获取字段列名，gorm 忽略的字段返回空
+func (model *Model) schemaColumn(path *embedPath, field *Field) string {
+	if field.Gorm.Tags["COLUMN"] == "-" {
+		return ""
+	}
+	bind := strings.Join(path.bind, ".")
+	for _, sf := range model.Schema.Fields {
+		if strings.Join(sf.BindNames, ".") == bind {
+			return sf.DBName
+		}
+	}
+	return ""
+}
+
+// FieldValue 获取结构体中的字段，value 为 model 结构体，嵌入的结构体指针为空时自动创建
+func (model *Model) FieldValue(value reflect.Value, name string) reflect.Value {
+	field, ok := model.Name2Field[name]
+	if !ok {
+		return reflect.Value{}
+	}
+	for i, x := range field.Index {
+		if i > 0 && value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(x)
+	}
+	return value
+}
+
+// FlattenNested 将具名嵌入结构体的json对象展开为 <key>.<子字段>，与 Json2Name 的字段一致
+func (model *Model) FlattenNested(data map[string]interface{}) map[string]interface{} {
+	if len(model.Nested) == 0 {
+		return data
+	}
+	result := make(map[string]interface{}, len(data))
+	var flatten func(prefix string, data map[string]interface{})
+	flatten = func(prefix string, data map[string]interface{}) {
+		for k, v := range data {
+			key := prefix + k
+			if obj, ok := v.(map[string]interface{}); ok && model.Nested[key] {
+				flatten(key+".", obj)
+				continue
+			}
+			result[key] = v
+		}
+	}
+	flatten("", data)
+	return result
+}
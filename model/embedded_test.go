@@ -0,0 +1,87 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type EmbeddedBase struct {
+	ID        int64     `gorm:"column:id;primaryKey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	Status    int32     `json:"status" default:"1"`
+}
+
+type EmbeddedAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email" default:"none"`
+}
+
+type EmbeddedCase struct {
+	EmbeddedBase
+	Status int32           `json:"state"`
+	Title  string          `json:"title"`
+	Author *EmbeddedAuthor `gorm:"embedded;embeddedPrefix:author_" json:"author"`
+}
+
+func TestEmbeddedModel(t *testing.T) {
+	for _, m := range []*Model{
+		NewModel(&EmbeddedCase{}),
+		NewModelWithDB(&gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{}}}, &EmbeddedCase{}),
+	} {
+		if m.PrimaryKey != "id" {
+			t.Errorf("embedded PrimaryKey fail, got=%s", m.PrimaryKey)
+		}
+		expected := map[string]string{
+			"id": "ID", "createdAt": "CreatedAt", "state": "Status", "title": "Title",
+			"author.name": "Author.Name", "author.email": "Author.Email",
+		}
+		if !reflect.DeepEqual(m.Json2Name, expected) {
+			t.Errorf("embedded Json2Name fail, got=%v", m.Json2Name)
+		}
+		if m.Name2Column["Author.Name"] != "author_name" || m.Name2Column["CreatedAt"] != "created_at" {
+			t.Errorf("embedded Name2Column fail, got=%v", m.Name2Column)
+		}
+		names := []string{"ID", "CreatedAt", "Status", "Title", "Author.Name", "Author.Email"}
+		if !reflect.DeepEqual(m.Names, names) {
+			t.Errorf("embedded Names fail, got=%v", m.Names)
+		}
+		if m.Name2Field["ID"].Namespace != "EmbeddedBase.ID" {
+			t.Errorf("embedded Namespace fail, got=%s", m.Name2Field["ID"].Namespace)
+		}
+	}
+}
+
+func TestEmbeddedParse(t *testing.T) {
+	m := NewModel(&EmbeddedCase{})
+	data, err := m.ParseFromQuery(map[string]string{"id": "3", "author.name": "a"})
+	if err != nil {
+		t.Fatalf("ParseFromQuery fail, error=%v", err)
+	}
+	value := data.(*EmbeddedCase)
+	if value.ID != 3 || value.Author == nil || value.Author.Name != "a" {
+		t.Errorf("ParseFromQuery embedded fail, got=%+v", value)
+	}
+
+	input := map[string]interface{}{"title": "t"}
+	data = m.New()
+	if err := m.ParseDefault(context.Background(), data, input); err != nil {
+		t.Fatalf("ParseDefault fail, error=%v", err)
+	}
+	value = data.(*EmbeddedCase)
+	if value.Author.Email != "none" || value.Status != 0 {
+		t.Errorf("ParseDefault embedded fail, got=%+v", value)
+	}
+	if _, ok := input["author.email"]; !ok {
+		t.Errorf("ParseDefault embedded input fail, got=%v", input)
+	}
+
+	flat := m.FlattenNested(map[string]interface{}{"author": map[string]interface{}{"name": "a"}, "title": "t"})
+	if !reflect.DeepEqual(flat, map[string]interface{}{"author.name": "a", "title": "t"}) {
+		t.Errorf("FlattenNested fail, got=%v", flat)
+	}
+}
@@ -10,6 +10,10 @@ import (
 
 type Field struct {
 	FieldType reflect.Type
+	// Index 字段在 model 结构体中的下标路径，嵌入结构体的字段包含多级，见 Model.FieldValue
+	Index []int
+	// Namespace 字段的结构体路径，如 Base.ID，用于部分校验
+	Namespace string
 
 	PrimaryKey bool
 
@@ -20,6 +24,10 @@ type Field struct {
 	Json    *Json
 	Default *Default
 	Operate *Operate
+	// Filter 允许的过滤操作符，见 filter tag
+	Filter []string
+	// Auto 自动维护的字段类型，见 auto tag
+	Auto string
 }
 
 func NewField(field reflect.StructField) *Field {
@@ -29,11 +37,15 @@ func NewField(field reflect.StructField) *Field {
 	}
 	instance := &Field{
 		FieldType:  fieldType,
+		Index:      field.Index,
+		Namespace:  field.Name,
 		PrimaryKey: false,
 		Json:       NewJson(field),
 		Gorm:       NewGorm(field),
 		Default:    NewDefault(field),
 		Operate:    NewOperate(field),
+		Filter:     NewFilter(field),
+		Auto:       NewAuto(field),
 	}
 	instance.JsonKey = instance.Json.Name
 	instance.DBKey = instance.Gorm.Column
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// 过滤操作符，查询参数形如 field__op=value 或 filter[field][op]=value
+const (
+	FilterEq         = "eq"
+	FilterNe         = "ne"
+	FilterGt         = "gt"
+	FilterGte        = "gte"
+	FilterLt         = "lt"
+	FilterLte        = "lte"
+	FilterIn         = "in"
+	FilterNin        = "nin"
+	FilterLike       = "like"
+	FilterStartsWith = "startswith"
+	FilterEndsWith   = "endswith"
+	FilterIsNull     = "isnull"
+	FilterBetween    = "between"
+)
+
+// FilterOperators 支持的全部过滤操作符
+var FilterOperators = []string{
+	FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterIn, FilterNin,
+	FilterLike, FilterStartsWith, FilterEndsWith, FilterIsNull, FilterBetween,
+}
+
+// ErrFilter 过滤参数错误
+var ErrFilter = errors.New("invalid filter")
+
+func isFilterOperator(op string) bool {
+	for _, o := range FilterOperators {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// NewFilter 解析 filter tag，返回字段允许的过滤操作符，如 filter:"eq,in,between"，filter:"*" 表示全部
+func NewFilter(field reflect.StructField) []string {
+	tag := strings.TrimSpace(field.Tag.Get("filter"))
+	if tag == "" {
+		return nil
+	}
+	if tag == "*" {
+		return FilterOperators
+	}
+	ops := make([]string, 0)
+	for _, op := range strings.Split(tag, ",") {
+		op = strings.ToLower(strings.TrimSpace(op))
+		if !isFilterOperator(op) {
+			panic(fmt.Sprintf("filter <%s> is inlegal in field <%s>", op, field.Name))
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// Condition 单个过滤条件
+type Condition struct {
+	Field    *Field
+	Operator string
+	Values   []interface{}
+}
+
+// Expression 生成参数化的查询条件
+func (c *Condition) Expression() clause.Expression {
+	column := clause.Column{Table: clause.CurrentTable, Name: c.Field.Gorm.Column}
+	switch c.Operator {
+	case FilterNe:
+		return clause.Neq{Column: column, Value: c.Values[0]}
+	case FilterGt:
+		return clause.Gt{Column: column, Value: c.Values[0]}
+	case FilterGte:
+		return clause.Gte{Column: column, Value: c.Values[0]}
+	case FilterLt:
+		return clause.Lt{Column: column, Value: c.Values[0]}
+	case FilterLte:
+		return clause.Lte{Column: column, Value: c.Values[0]}
+	case FilterIn:
+		return clause.IN{Column: column, Values: c.Values}
+	case FilterNin:
+		return clause.Not(clause.IN{Column: column, Values: c.Values})
+	case FilterLike:
+		return LikeExpression(column, "%"+EscapeLike(c.Values[0].(string))+"%")
+	case FilterStartsWith:
+		return LikeExpression(column, EscapeLike(c.Values[0].(string))+"%")
+	case FilterEndsWith:
+		return LikeExpression(column, "%"+EscapeLike(c.Values[0].(string)))
+	case FilterIsNull:
+		if c.Values[0].(bool) {
+			return clause.Expr{SQL: "? IS NULL", Vars: []interface{}{column}}
+		}
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{column}}
+	case FilterBetween:
+		return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{column, c.Values[0], c.Values[1]}}
+	}
+	return clause.Eq{Column: column, Value: c.Values[0]}
+}
+
+// LikeEscape LIKE 的转义字符，不使用反斜杠以兼容各数据库对字符串中反斜杠的不同处理
+const LikeEscape = "!"
+
+// EscapeLike 转义 LIKE 中的通配符 % 与 _，与 LikeEscape 配合使用
+func EscapeLike(s string) string {
+	return likeReplacer.Replace(s)
+}
+
+var likeReplacer = strings.NewReplacer(LikeEscape, LikeEscape+LikeEscape, "%", LikeEscape+"%", "_", LikeEscape+"_")
+
+// LikeExpression 生成带 ESCAPE 的 LIKE 条件，value 中的用户输入需经过 EscapeLike 转义
+func LikeExpression(column interface{}, value string) clause.Expression {
+	return clause.Expr{SQL: "? LIKE ? ESCAPE '" + LikeEscape + "'", Vars: []interface{}{column, value}}
+}
+
+// parseFilterKey 解析查询参数名，返回json字段与操作符，非过滤参数返回 ok=false
+//
+//	支持 field__op、filter[field][op]，filter[field] 等同于 eq；
+//	field__op 仅在 field 为 model 的json字段时视为过滤参数，避免与 utm__source 等其他参数冲突
+func (model *Model) parseFilterKey(key string) (string, string, bool) {
+	if strings.HasPrefix(key, "filter[") && strings.HasSuffix(key, "]") {
+		parts := strings.Split(key[len("filter["):len(key)-1], "][")
+		if len(parts) == 1 {
+			return parts[0], FilterEq, true
+		}
+		if len(parts) == 2 {
+			return parts[0], parts[1], true
+		}
+		return key, "", true
+	}
+	index := strings.LastIndex(key, "__")
+	if index <= 0 {
+		return "", "", false
+	}
+	if _, ok := model.Json2Name[key[:index]]; !ok {
+		return "", "", false
+	}
+	return key[:index], key[index+2:], true
+}
+
+// ParseFilters 解析查询参数中的过滤条件
+//
+//	allowed 为json字段到允许操作符的映射，未设置的字段使用 filter tag；
+//	字段不存在、不允许过滤或操作符不允许时返回错误
+func (model *Model) ParseFilters(query map[string]string, allowed map[string][]string) ([]*Condition, error) {
+	conditions := make([]*Condition, 0)
+	for key, value := range query {
+		jsonKey, op, ok := model.parseFilterKey(key)
+		if !ok {
+			continue
+		}
+		op = strings.ToLower(op)
+		name, ok := model.Json2Name[jsonKey]
+		if !ok || model.Name2Field[name].Gorm.Column == "" {
+			return nil, fmt.Errorf("%w: unknown field %s", ErrFilter, jsonKey)
+		}
+		field := model.Name2Field[name]
+		ops, ok := allowed[jsonKey]
+		if !ok {
+			ops = field.Filter
+		}
+		if !containsString(ops, op) {
+			return nil, fmt.Errorf("%w: operator %s is not allowed on field %s", ErrFilter, op, jsonKey)
+		}
+		condition, err := field.parseCondition(op, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFilter, err.Error())
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+func (field *Field) parseCondition(op string, value string) (*Condition, error) {
+	condition := &Condition{
+		Field:    field,
+		Operator: op,
+	}
+	switch op {
+	case FilterIsNull:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s isnull need a bool value", field.JsonKey)
+		}
+		condition.Values = []interface{}{v}
+		return condition, nil
+	case FilterLike, FilterStartsWith, FilterEndsWith:
+		if field.FieldType.Kind() != reflect.String {
+			return nil, fmt.Errorf("%s %s only support string field", field.JsonKey, op)
+		}
+		condition.Values = []interface{}{value}
+		return condition, nil
+	}
+	values := []string{value}
+	if op == FilterIn || op == FilterNin || op == FilterBetween {
+		values = strings.Split(value, ",")
+		for i, v := range values {
+			values[i] = strings.TrimSpace(v)
+		}
+	}
+	if op == FilterBetween && len(values) != 2 {
+		return nil, fmt.Errorf("%s between need two values", field.JsonKey)
+	}
+	for _, v := range values {
+		vv, err := field.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		condition.Values = append(condition.Values, vv)
+	}
+	return condition, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
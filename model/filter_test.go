@@ -0,0 +1,99 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type FilterCase struct {
+	ID     int64  `gorm:"column:id;primaryKey" json:"id" filter:"*"`
+	Status int32  `gorm:"column:status" json:"status" filter:"eq,in,between"`
+	Name   string `gorm:"column:name" json:"name" filter:"like,startswith,isnull"`
+	Remark string `gorm:"column:remark" json:"remark"`
+}
+
+func TestNewFilter(t *testing.T) {
+	m := NewModel(&FilterCase{})
+	if len(m.Name2Field["ID"].Filter) != len(FilterOperators) {
+		t.Errorf("TestNewFilter * fail, got=%v", m.Name2Field["ID"].Filter)
+	}
+	if !reflect.DeepEqual(m.Name2Field["Status"].Filter, []string{"eq", "in", "between"}) {
+		t.Errorf("TestNewFilter fail, got=%v", m.Name2Field["Status"].Filter)
+	}
+	if m.Name2Field["Remark"].Filter != nil {
+		t.Errorf("TestNewFilter empty fail, got=%v", m.Name2Field["Remark"].Filter)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("TestNewFilter illegal operator should panic")
+		}
+	}()
+	type IllegalCase struct {
+		Name string `json:"name" filter:"regex"`
+	}
+	NewModel(&IllegalCase{})
+}
+
+func TestParseFilters(t *testing.T) {
+	db := dryRunDB(t)
+	m := NewModel(&FilterCase{})
+	cases := []struct {
+		query  map[string]string
+		allow  map[string][]string
+		expect string
+		vars   []interface{}
+	}{
+		{map[string]string{"id__gte": "3"}, nil, "`filter_cases`.`id` >= ?", []interface{}{int64(3)}},
+		{map[string]string{"filter[id][ne]": "3"}, nil, "`filter_cases`.`id` <> ?", []interface{}{int64(3)}},
+		{map[string]string{"filter[status]": "1"}, nil, "`filter_cases`.`status` = ?", []interface{}{int32(1)}},
+		{map[string]string{"status__in": "1, 2"}, nil, "`filter_cases`.`status` IN (?,?)", []interface{}{int32(1), int32(2)}},
+		{map[string]string{"id__nin": "1,2"}, nil, "`filter_cases`.`id` NOT IN (?,?)", []interface{}{int64(1), int64(2)}},
+		{map[string]string{"status__between": "1,5"}, nil, "`filter_cases`.`status` BETWEEN ? AND ?", []interface{}{int32(1), int32(5)}},
+		{map[string]string{"name__like": "a"}, nil, "`filter_cases`.`name` LIKE ? ESCAPE '!'", []interface{}{"%a%"}},
+		{map[string]string{"name__startswith": "a"}, nil, "`filter_cases`.`name` LIKE ? ESCAPE '!'", []interface{}{"a%"}},
+		{map[string]string{"name__isnull": "false"}, nil, "`filter_cases`.`name` IS NOT NULL", nil},
+		{map[string]string{"remark__endswith": "a"}, map[string][]string{"remark": {"endswith"}}, "`filter_cases`.`remark` LIKE ? ESCAPE '!'", []interface{}{"%a"}},
+		{map[string]string{"name__like": `50%_a!\`}, nil, "`filter_cases`.`name` LIKE ? ESCAPE '!'", []interface{}{`%50!%!_a!!\%`}},
+		{map[string]string{"page": "1", "name": "a", "utm__source": "x", "unknown__eq": "1"}, nil, "", nil},
+	}
+	for _, c := range cases {
+		conditions, err := m.ParseFilters(c.query, c.allow)
+		if err != nil {
+			t.Errorf("TestParseFilters fail, query=%v error=%v", c.query, err)
+			continue
+		}
+		stmt := db.Model(&FilterCase{})
+		for _, condition := range conditions {
+			stmt = stmt.Where(condition.Expression())
+		}
+		stmt = stmt.Find(&[]FilterCase{})
+		sql := stmt.Statement.SQL.String()
+		expect := "SELECT * FROM `filter_cases`"
+		if c.expect != "" {
+			expect += " WHERE " + c.expect
+		}
+		if sql != expect {
+			t.Errorf("TestParseFilters sql fail, query=%v expect=%s got=%s", c.query, expect, sql)
+		}
+		if len(c.vars) > 0 && !reflect.DeepEqual(stmt.Statement.Vars, c.vars) {
+			t.Errorf("TestParseFilters vars fail, query=%v expect=%v got=%v", c.query, c.vars, stmt.Statement.Vars)
+		}
+	}
+
+	invalid := []map[string]string{
+		{"filter[unknown]": "1"},
+		{"status__gt": "1"},
+		{"remark__eq": "1"},
+		{"status__eq": "abc"},
+		{"status__between": "1"},
+		{"name__isnull": "abc"},
+		{"id__like": "1"},
+		{"filter[id][eq][x]": "1"},
+	}
+	for _, query := range invalid {
+		if _, err := m.ParseFilters(query, map[string][]string{"id": {"like"}}); !errors.Is(err, ErrFilter) {
+			t.Errorf("TestParseFilters invalid fail, query=%v got=%v", query, err)
+		}
+	}
+}
@@ -12,7 +12,7 @@ type Gorm struct {
 }
 
 // NewGorm 创建一个gorm tag分析的struct
-// 注意这里只使用了默认的列名策略，支持自定义策略，需要透传db对象，过于复杂
+// 这里只使用默认的列名策略，Model 中的列名按 NewModelWithDB、NewParamsWithDB 传入 db 的策略生成
 func NewGorm(field reflect.StructField) *Gorm {
 	tag := field.Tag.Get("gorm")
 	tagMap := schema.ParseTagSetting(tag, ";")
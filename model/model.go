@@ -6,16 +6,21 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 // Model model解析类
 type Model struct {
 	ModelInterface interface{}
 	ModelType      reflect.Type
-	// DB 主键标识
+	// DB 主键标识，联合主键时为第一列
 	PrimaryKey string
+	// PrimaryKeys 全部主键列，按结构体顺序
+	PrimaryKeys []string
 
 	// struct名到json字段
 	Name2Json map[string]string
@@ -27,21 +32,67 @@ type Model struct {
 	Column2Name map[string]string
 	// struct名到db列
 	Name2Field map[string]*Field
+	// json字段到虚拟字段，见 IComputedFields
+	Computed map[string]*ComputedField
+	// Names 按结构体顺序的全部字段名，嵌入结构体的字段已展开
+	Names []string
+	// Nested 具名嵌入结构体的json字段，其子字段的json字段为 <key>.<子字段>
+	Nested map[string]bool
+	// Schema GORM 解析的表结构，通过 NewModelWithDB 创建时有效，列名与表名以此为准
+	Schema *schema.Schema
+
+	// namer 未解析 Schema 时生成列名的策略
+	namer schema.Namer
 }
 
 // NewModel NewParser Model 实例化，确保尽在启动阶段调用，而不会在请求处理阶段调用
+//
+//	使用默认的列名策略，不解析 Schema
 func NewModel(model interface{}) *Model {
+	return newModel(model, schema.NamingStrategy{}, nil)
+}
+
+// NewModelWithDB 使用 db 的 NamingStrategy 解析列名与表名，保证与 GORM 实际读写的列一致
+//
+//	用于数据表的 model，GORM 无法解析时 panic；db 未初始化（如测试中的 &gorm.DB{}）时与 NewModel 相同
+func NewModelWithDB(db *gorm.DB, model interface{}) *Model {
+	if db == nil || db.Config == nil {
+		return NewModel(model)
+	}
+	namer := dbNamer(db)
+	sch, err := schema.Parse(model, &sync.Map{}, namer)
+	if err != nil {
+		panic(fmt.Sprintf("parse model schema fail, %s", err.Error()))
+	}
+	return newModel(model, namer, sch)
+}
+
+// NewParamsWithDB 非数据表的结构体（如检索参数）使用 db 的列名策略，不解析 Schema
+func NewParamsWithDB(db *gorm.DB, model interface{}) *Model {
+	if db == nil || db.Config == nil {
+		return NewModel(model)
+	}
+	return newModel(model, dbNamer(db), nil)
+}
+
+// dbNamer db 的列名策略，未设置时为默认策略
+func dbNamer(db *gorm.DB) schema.Namer {
+	if db.NamingStrategy != nil {
+		return db.NamingStrategy
+	}
+	return schema.NamingStrategy{}
+}
+
+func newModel(model interface{}, namer schema.Namer, sch *schema.Schema) *Model {
 	mt := reflect.TypeOf(model)
-	mv := reflect.ValueOf(model)
 	if mt.Kind() == reflect.Ptr {
 		mt = mt.Elem()
 	}
-	if mv.Kind() == reflect.Ptr {
-		mv = mv.Elem()
-	}
 	p := &Model{
 		ModelInterface: model,
 		ModelType:      mt,
+		Schema:         sch,
+		namer:          namer,
 	}
 	p.Init()
 	return p
@@ -53,31 +104,41 @@ func (model *Model) Init() {
 	model.Json2Name = make(map[string]string)
 	model.Column2Name = make(map[string]string)
 	model.Name2Field = make(map[string]*Field)
+	model.Names = make([]string, 0)
+	model.PrimaryKeys = make([]string, 0)
+	model.Nested = make(map[string]bool)
 
 	t := model.ModelType
-	// 遍历结构体中所有字段
-	for i := 0; i < t.NumField(); i++ {
-		// 单个结构体字段
-		f := t.Field(i)
-		name := f.Name
-		field := NewField(f)
-		model.Name2Field[name] = field
-		if len(field.Json.Name) > 0 {
-			model.Name2Json[name] = field.Json.Name
-			model.Json2Name[field.Json.Name] = name
-		}
-		if len(field.Gorm.Column) > 0 {
-			model.Name2Column[name] = field.Gorm.Column
-			model.Column2Name[field.Gorm.Column] = name
-		}
-		if field.PrimaryKey && len(model.PrimaryKey) == 0 {
-			model.PrimaryKey = field.Gorm.Column
-		} else if field.PrimaryKey && len(model.PrimaryKey) != 0 {
-			panic(fmt.Sprintf("model <%s> can only have one PrimaryKey", model.ModelType.Name()))
+	// 遍历结构体中所有字段，包括匿名嵌入与 gorm:"embedded" 的结构体
+	model.initFields(t, &embedPath{})
+	sort.Slice(model.Names, func(i, j int) bool {
+		return lessIndex(model.Name2Field[model.Names[i]].Index, model.Name2Field[model.Names[j]].Index)
+	})
+	sort.SliceStable(model.PrimaryKeys, func(i, j int) bool {
+		return lessIndex(model.Name2Field[model.Column2Name[model.PrimaryKeys[i]]].Index, model.Name2Field[model.Column2Name[model.PrimaryKeys[j]]].Index)
+	})
+	if len(model.PrimaryKeys) > 0 {
+		model.PrimaryKey = model.PrimaryKeys[0]
+	}
+	model.Computed = NewComputedFields(t)
+	for key := range model.Computed {
+		if _, ok := model.Json2Name[key]; ok {
+			panic(fmt.Sprintf("computed field <%s> conflicts with field in model <%s>", key, t.Name()))
 		}
 	}
 }
 
+// TableName 获取表名，优先使用 Schema 解析的表名及 model 的 TableName 方法
+func (model *Model) TableName() string {
+	if model.Schema != nil {
+		return model.Schema.Table
+	}
+	if tabler, ok := model.ModelInterface.(schema.Tabler); ok {
+		return tabler.TableName()
+	}
+	return schema.NamingStrategy{}.TableName(model.ModelType.Name())
+}
+
 // New 实例化具体Model
 func (model *Model) New() interface{} {
 	return reflect.New(model.ModelType).Interface()
@@ -109,7 +170,7 @@ func (model *Model) ParseFromQuery(query map[string]string) (interface{}, error)
 			if err != nil {
 				return nil, err
 			}
-			fv := dv.FieldByName(name)
+			fv := model.FieldValue(dv, name)
 			makePtr(fv).Set(reflect.ValueOf(vv))
 		}
 	}
@@ -120,7 +181,7 @@ func (model *Model) ParseFromQuery(query map[string]string) (interface{}, error)
 func (model *Model) ParseDefault(ctx context.Context, data interface{}, input map[string]interface{}) error {
 	value := reflect.Indirect(reflect.ValueOf(data))
 	for name, field := range model.Name2Field {
-		fv := value.FieldByName(name)
+		fv := model.FieldValue(value, name)
 		if jsonKey, ok := model.Name2Json[name]; ok {
 			if _, ok2 := input[jsonKey]; !ok2 {
 				if field.HaveDefaultValue() {
@@ -148,7 +209,7 @@ func (model *Model) ParseDefaultWithKeys(ctx context.Context, data interface{},
 		if !ok {
 			continue
 		}
-		fv := value.FieldByName(name)
+		fv := model.FieldValue(value, name)
 		if jsonKey, ok := model.Name2Json[name]; ok {
 			if _, ok2 := input[jsonKey]; !ok2 {
 				if field.HaveDefaultValue() {
@@ -172,26 +233,24 @@ func (model *Model) CheckPrimaryKey() error {
 	return nil
 }
 
-// ParsePrimaryKey 注意返回的是model的指针
+// ParsePrimaryKey 解析url中的主键，联合主键时返回db列到值的映射，见 ParseKey
 func (model *Model) ParsePrimaryKey(primaryKey string) (interface{}, error) {
 	if err := model.CheckPrimaryKey(); err != nil {
 		return nil, err
 	}
-	if name, ok := model.Column2Name[model.PrimaryKey]; ok {
-		field, err := model.Name2Field[name].Parse(primaryKey)
-		if err != nil {
-			return nil, err
-		}
-		return field, nil
+	key, err := model.ParseKey(primaryKey, model.PrimaryKeys)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("primaryKey need a gorm column")
+	return model.KeyValue(key), nil
 }
 
+// FieldNames 获取json数据对应字段的完整结构体路径，用于部分校验
 func (model *Model) FieldNames(data map[string]interface{}) []string {
 	names := make([]string, 0)
 	for k := range data {
 		if name, ok := model.Json2Name[k]; ok {
-			names = append(names, name)
+			names = append(names, model.Name2Field[name].Namespace)
 		}
 	}
 	return names
@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
 	"github.com/lookupearth/restful/field"
 )
 
@@ -162,3 +166,55 @@ func TestModelParseDefault(t *testing.T) {
 		}
 	}
 }
+
+type NamingCase struct {
+	ID       int64  `gorm:"primaryKey" json:"id"`
+	UserName string `json:"userName"`
+	Nick     string `gorm:"column:nick_name" json:"nick"`
+	Ignored  string `gorm:"-" json:"ignored"`
+	Virtual  string `gorm:"column:-" json:"virtual"`
+}
+
+// NamingParams 检索参数等非数据表的结构体，GORM 无法解析 []string 字段
+type NamingParams struct {
+	UserName []string `json:"userName"`
+}
+
+func TestNewModelWithDB(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{
+		TablePrefix:   "t_",
+		SingularTable: true,
+		NameReplacer:  strings.NewReplacer("UserName", "Login"),
+	}}}
+	m := NewModelWithDB(db, &NamingCase{})
+	if m.TableName() != "t_naming_case" {
+		t.Errorf("TableName fail, got=%s", m.TableName())
+	}
+	expected := map[string]string{"ID": "id", "UserName": "login", "Nick": "nick_name"}
+	if !reflect.DeepEqual(m.Name2Column, expected) {
+		t.Errorf("Name2Column fail, got=%v", m.Name2Column)
+	}
+	if m.PrimaryKey != "id" || m.Name2Field["UserName"].DBKey != "login" {
+		t.Errorf("PrimaryKey or DBKey fail")
+	}
+
+	// 非数据表的结构体只使用 db 的列名策略
+	m = NewParamsWithDB(db, &NamingParams{})
+	if m.Schema != nil || m.Name2Column["UserName"] != "login" {
+		t.Errorf("NewParamsWithDB fail, got=%v", m.Name2Column)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("NewModelWithDB unsupported model should panic")
+			}
+		}()
+		NewModelWithDB(db, &NamingParams{})
+	}()
+
+	// 未初始化的 db 使用默认策略
+	m = NewModelWithDB(&gorm.DB{}, &NamingCase{})
+	if m.Schema != nil || m.Name2Column["UserName"] != "user_name" {
+		t.Errorf("NewModelWithDB without config fail, got=%v", m.Name2Column)
+	}
+}
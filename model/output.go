@@ -0,0 +1,228 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Output 输出模型，将 model 的json数据映射为独立的输出结构体，使接口结构与表结构解耦
+//
+//	字段按json名映射，可通过 from tag 指定来源：
+//	from:"name"       重命名，取 model 的 name 字段（含虚拟字段）
+//	from:"meta.city"  取嵌套对象（如 field.JSONObject）中的值
+//	from:"."          嵌套结构体从当前对象映射，用于将平铺字段组合为子对象
+//	结构体或结构体切片类型的字段作为嵌套 DTO，按其自身字段递归映射。
+//	同样的结构也可作为版本输入模型，通过 Unmap 反向映射为 model 的json数据。
+type Output struct {
+	Type   reflect.Type
+	fields []*outputField
+}
+
+type outputField struct {
+	JsonKey string
+	From    []string
+	Self    bool
+	Nested  *Output
+	Slice   bool
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// NewOutput 解析输出结构体，确保仅在启动阶段调用
+func NewOutput(output interface{}) *Output {
+	t := reflect.TypeOf(output)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("output model <%s> should be a struct", t))
+	}
+	return newOutput(t)
+}
+
+func newOutput(t reflect.Type) *Output {
+	o := &Output{
+		Type:   t,
+		fields: make([]*outputField, 0),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		jsonKey := NewJson(f).Name
+		if jsonKey == "" {
+			continue
+		}
+		field := &outputField{JsonKey: jsonKey}
+		from := strings.TrimSpace(f.Tag.Get("from"))
+		if from == "." {
+			field.Self = true
+		} else if from != "" {
+			field.From = strings.Split(from, ".")
+		} else {
+			field.From = []string{jsonKey}
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Slice {
+			field.Slice = true
+			ft = ft.Elem()
+		}
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if isDTO(ft) {
+			field.Nested = newOutput(ft)
+		} else if field.Self {
+			panic(fmt.Sprintf("output field <%s> with from:\".\" should be a struct", f.Name))
+		}
+		if field.Self && field.Slice {
+			panic(fmt.Sprintf("output field <%s> with from:\".\" can not be a slice", f.Name))
+		}
+		o.fields = append(o.fields, field)
+	}
+	return o
+}
+
+// JsonKeys 按结构体顺序返回输出的json字段
+func (o *Output) JsonKeys() []string {
+	keys := make([]string, 0, len(o.fields))
+	for _, f := range o.fields {
+		keys = append(keys, f.JsonKey)
+	}
+	return keys
+}
+
+// isDTO 判断是否为需要递归映射的结构体，时间与自定义json解析的类型按普通值处理
+func isDTO(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if t.ConvertibleTo(reflect.TypeOf(time.Time{})) {
+		return false
+	}
+	return !reflect.PtrTo(t).Implements(jsonUnmarshalerType)
+}
+
+func lookupPath(source map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = source
+	for _, key := range path {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// Map 将 model 的json数据映射为输出结构的json数据，来源不存在的字段忽略
+func (o *Output) Map(source map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(o.fields))
+	for _, field := range o.fields {
+		var value interface{} = source
+		if !field.Self {
+			var ok bool
+			value, ok = lookupPath(source, field.From)
+			if !ok {
+				continue
+			}
+		}
+		if field.Nested != nil && value != nil {
+			if field.Slice {
+				items, ok := value.([]interface{})
+				if !ok {
+					continue
+				}
+				mapped := make([]interface{}, 0, len(items))
+				for _, item := range items {
+					if obj, ok := item.(map[string]interface{}); ok {
+						mapped = append(mapped, field.Nested.Map(obj))
+					}
+				}
+				value = mapped
+			} else {
+				obj, ok := value.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				value = field.Nested.Map(obj)
+			}
+		}
+		result[field.JsonKey] = value
+	}
+	return result
+}
+
+// Unmap 将输出结构的json数据反向映射为 model 的json数据，只处理 data 中存在的字段
+func (o *Output) Unmap(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	for _, field := range o.fields {
+		value, ok := data[field.JsonKey]
+		if !ok {
+			continue
+		}
+		if field.Nested != nil && value != nil {
+			if field.Slice {
+				items, ok := value.([]interface{})
+				if !ok {
+					continue
+				}
+				unmapped := make([]interface{}, 0, len(items))
+				for _, item := range items {
+					if obj, ok := item.(map[string]interface{}); ok {
+						unmapped = append(unmapped, field.Nested.Unmap(obj))
+					}
+				}
+				value = unmapped
+			} else {
+				obj, ok := value.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				value = field.Nested.Unmap(obj)
+			}
+		}
+		if field.Self {
+			if obj, ok := value.(map[string]interface{}); ok {
+				for k, v := range obj {
+					result[k] = v
+				}
+			}
+			continue
+		}
+		setPath(result, field.From, value)
+	}
+	return result
+}
+
+func setPath(target map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		obj, ok := target[key].(map[string]interface{})
+		if !ok {
+			obj = make(map[string]interface{})
+			target[key] = obj
+		}
+		target = obj
+	}
+	target[path[len(path)-1]] = value
+}
+
+// Convert 将 model 的json数据转换为输出结构体指针
+func (o *Output) Convert(source map[string]interface{}) (interface{}, error) {
+	b, err := json.Marshal(o.Map(source))
+	if err != nil {
+		return nil, err
+	}
+	data := reflect.New(o.Type).Interface()
+	if err := json.Unmarshal(b, data); err != nil {
+		return nil, fmt.Errorf("convert to %s failed, %s", o.Type.Name(), err.Error())
+	}
+	return data, nil
+}
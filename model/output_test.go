@@ -0,0 +1,94 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type OutputAuthor struct {
+	Name string `json:"name" from:"authorName"`
+	City string `json:"city" from:"meta.city"`
+}
+
+type OutputTag struct {
+	Label string `json:"label" from:"name"`
+}
+
+type OutputCase struct {
+	ID      int64        `json:"id"`
+	Title   string       `json:"title" from:"name"`
+	Author  OutputAuthor `json:"author" from:"."`
+	Tags    []OutputTag  `json:"tags"`
+	Created time.Time    `json:"created" from:"createTime"`
+	Ignore  string       `json:"-"`
+}
+
+func TestOutput(t *testing.T) {
+	out := NewOutput(&OutputCase{})
+	source := map[string]interface{}{
+		"id":         1,
+		"name":       "a",
+		"authorName": "b",
+		"meta":       map[string]interface{}{"city": "c"},
+		"tags":       []interface{}{map[string]interface{}{"name": "x", "id": 1}},
+		"createTime": "2024-01-02T03:04:05Z",
+		"secret":     "s",
+	}
+	data, err := out.Convert(source)
+	if err != nil {
+		t.Fatalf("Convert fail, error=%v", err)
+	}
+	b, _ := json.Marshal(data)
+	expected := `{"id":1,"title":"a","author":{"name":"b","city":"c"},"tags":[{"label":"x"}],"created":"2024-01-02T03:04:05Z"}`
+	if string(b) != expected {
+		t.Errorf("Convert fail, got=%s", b)
+	}
+
+	// 来源不存在的字段忽略
+	mapped := out.Map(map[string]interface{}{"id": 2})
+	if len(mapped) != 2 || mapped["id"] != 2 {
+		t.Errorf("Map missing fail, got=%v", mapped)
+	}
+
+	if _, err := out.Convert(map[string]interface{}{"id": "x"}); err == nil {
+		t.Errorf("Convert with wrong type should fail")
+	}
+}
+
+func TestOutputInvalid(t *testing.T) {
+	cases := []interface{}{
+		1,
+		&struct {
+			Name string `json:"name" from:"."`
+		}{},
+		&struct {
+			Tags []OutputTag `json:"tags" from:"."`
+		}{},
+	}
+	for i, c := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("case %d NewOutput should panic", i)
+				}
+			}()
+			NewOutput(c)
+		}()
+	}
+}
+
+func TestOutputUnmap(t *testing.T) {
+	out := NewOutput(&OutputCase{})
+	data := out.Unmap(map[string]interface{}{
+		"title":  "a",
+		"author": map[string]interface{}{"name": "b", "city": "c"},
+		"tags":   []interface{}{map[string]interface{}{"label": "x"}},
+		"other":  1,
+	})
+	b, _ := json.Marshal(data)
+	expected := `{"authorName":"b","meta":{"city":"c"},"name":"a","tags":[{"name":"x"}]}`
+	if string(b) != expected {
+		t.Errorf("Unmap fail, got=%s", b)
+	}
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// KeySeparators 联合主键在url中的分隔符，如 /items/1,2 或 /items/1:2
+var KeySeparators = []string{",", ":"}
+
+// IsCompositeKey 是否为联合主键
+func (model *Model) IsCompositeKey() bool {
+	return len(model.PrimaryKeys) > 1
+}
+
+// ParseKey 按 columns 解析url中的key，多列时按 KeySeparators 分隔，返回db列到值的映射
+func (model *Model) ParseKey(key string, columns []string) (map[string]interface{}, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("model need a PrimaryKey")
+	}
+	values := []string{key}
+	if len(columns) > 1 {
+		values = nil
+		for _, sep := range KeySeparators {
+			if parts := strings.Split(key, sep); len(parts) == len(columns) {
+				values = parts
+				break
+			}
+		}
+		if values == nil {
+			return nil, fmt.Errorf("key %s should have %d parts", key, len(columns))
+		}
+	}
+	result := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		name, ok := model.Column2Name[column]
+		if !ok {
+			return nil, fmt.Errorf("key column %s not found", column)
+		}
+		value, err := model.Name2Field[name].Parse(values[i])
+		if err != nil {
+			return nil, err
+		}
+		result[column] = value
+	}
+	return result, nil
+}
+
+// KeyValue 单列时返回值本身，联合主键时返回db列到值的映射，用于事件等场景
+func (model *Model) KeyValue(key map[string]interface{}) interface{} {
+	if len(key) == 1 {
+		for _, v := range key {
+			return v
+		}
+	}
+	return key
+}
+
+// PrimaryKeyValue 获取 model 数据的主键，联合主键时返回db列到值的映射
+func (model *Model) PrimaryKeyValue(data interface{}) interface{} {
+	if len(model.PrimaryKeys) == 0 {
+		return nil
+	}
+	value := reflect.Indirect(reflect.ValueOf(data))
+	key := make(map[string]interface{}, len(model.PrimaryKeys))
+	for _, column := range model.PrimaryKeys {
+		key[column] = model.FieldValue(value, model.Column2Name[column]).Interface()
+	}
+	return model.KeyValue(key)
+}
+
+// WhereKey 添加key条件，key 为 db列到值的映射，按 columns 顺序生成
+func (model *Model) WhereKey(query *gorm.DB, columns []string, key map[string]interface{}) *gorm.DB {
+	exprs := make([]clause.Expression, 0, len(columns))
+	for _, column := range columns {
+		exprs = append(exprs, clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: column},
+			Value:  key[column],
+		})
+	}
+	return query.Where(clause.And(exprs...))
+}
+
+// WherePrimaryKey 添加主键条件，pk 为 PrimaryKeyValue 的返回值
+func (model *Model) WherePrimaryKey(query *gorm.DB, pk interface{}) *gorm.DB {
+	key, ok := pk.(map[string]interface{})
+	if !ok {
+		key = map[string]interface{}{model.PrimaryKey: pk}
+	}
+	return model.WhereKey(query, model.PrimaryKeys, key)
+}
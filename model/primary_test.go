@@ -0,0 +1,53 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+type CompositeCase struct {
+	UserID  int64  `gorm:"column:user_id;primaryKey" json:"userId"`
+	GroupID string `gorm:"column:group_id;primaryKey" json:"groupId"`
+	Role    string `gorm:"column:role" json:"role"`
+}
+
+func TestCompositeKey(t *testing.T) {
+	m := NewModel(&CompositeCase{})
+	if !m.IsCompositeKey() || m.PrimaryKey != "user_id" || !reflect.DeepEqual(m.PrimaryKeys, []string{"user_id", "group_id"}) {
+		t.Fatalf("PrimaryKeys fail, got=%v", m.PrimaryKeys)
+	}
+	expected := map[string]interface{}{"user_id": int64(1), "group_id": "a"}
+	for _, key := range []string{"1,a", "1:a"} {
+		pk, err := m.ParsePrimaryKey(key)
+		if err != nil || !reflect.DeepEqual(pk, expected) {
+			t.Errorf("ParsePrimaryKey %s fail, got=%v, error=%v", key, pk, err)
+		}
+	}
+	for _, key := range []string{"1", "x,a", "1,a,b"} {
+		if _, err := m.ParsePrimaryKey(key); err == nil {
+			t.Errorf("ParsePrimaryKey %s should fail", key)
+		}
+	}
+	if pk := m.PrimaryKeyValue(&CompositeCase{UserID: 1, GroupID: "a"}); !reflect.DeepEqual(pk, expected) {
+		t.Errorf("PrimaryKeyValue fail, got=%v", pk)
+	}
+
+	single := NewModel(&Activity{})
+	if pk, _ := single.ParsePrimaryKey("3"); pk != int64(3) {
+		t.Errorf("ParsePrimaryKey single fail, got=%v", pk)
+	}
+	if pk := single.PrimaryKeyValue(Activity{ID: 3}); pk != int64(3) {
+		t.Errorf("PrimaryKeyValue single fail, got=%v", pk)
+	}
+}
+
+func TestWherePrimaryKey(t *testing.T) {
+	db := dryRunDB(t)
+	m := NewModel(&CompositeCase{})
+	pk := map[string]interface{}{"user_id": int64(1), "group_id": "a"}
+	stmt := m.WherePrimaryKey(db.Model(m.New()), pk).Find(m.NewSlice()).Statement
+	sql := "SELECT * FROM `composite_cases` WHERE `composite_cases`.`user_id` = ? AND `composite_cases`.`group_id` = ?"
+	if stmt.SQL.String() != sql || !reflect.DeepEqual(stmt.Vars, []interface{}{int64(1), "a"}) {
+		t.Errorf("WherePrimaryKey fail, got=%s %v", stmt.SQL.String(), stmt.Vars)
+	}
+}
@@ -0,0 +1,195 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/now"
+)
+
+// defaultProvider 与字段类型无关的默认值提供者
+type defaultProvider struct {
+	prepare DefaultPrepareFunc
+	fn      DefaultFunc
+}
+
+var defaultProviders = make(map[string]*defaultProvider)
+
+// ProviderPrefix default tag 以此开头时为 provider，否则按字面值解析，如 default:"uuid" 的默认值为字符串 uuid
+const ProviderPrefix = "@"
+
+// RegisterDefaultProvider 注册与字段类型无关的默认值提供者，用法为 default:"@<name>" 或 default:"@<name>:<params>"
+//
+//	prepare 在 NewModel 阶段校验字段类型与参数，返回的值会传给 fn；fn 返回的值会被转换为字段类型。
+//	内置的 provider 有：
+//	@uuid          随机 UUID（v4），仅支持字符串
+//	@ulid          ULID，按时间有序，仅支持字符串
+//	@ctx:<key>     取 context 中的值，gin.Context 中为 c.Set 设置的值
+//	@header:<name> 取请求头
+//	@now+24h       当前时间加偏移，支持 time.Duration 格式，仅支持时间类型
+func RegisterDefaultProvider(name string, prepare DefaultPrepareFunc, fn DefaultFunc) {
+	defaultProviders[name] = &defaultProvider{
+		prepare: prepare,
+		fn:      fn,
+	}
+}
+
+func init() {
+	RegisterDefaultProvider("uuid", prepareString, func(context.Context, interface{}) interface{} {
+		return NewUUID()
+	})
+	RegisterDefaultProvider("ulid", prepareString, func(context.Context, interface{}) interface{} {
+		return NewULID()
+	})
+	RegisterDefaultProvider("ctx", prepareKey, func(ctx context.Context, v interface{}) interface{} {
+		if ctx == nil {
+			return nil
+		}
+		return ctx.Value(v.(string))
+	})
+	RegisterDefaultProvider("header", prepareKey, func(ctx context.Context, v interface{}) interface{} {
+		if c, ok := ctx.(interface{ GetHeader(string) string }); ok {
+			return c.GetHeader(v.(string))
+		}
+		return nil
+	})
+	RegisterDefaultProvider("now", prepareNow, func(ctx context.Context, v interface{}) interface{} {
+		return time.Now().Add(v.(time.Duration))
+	})
+}
+
+func prepareString(t reflect.Type, params string) (interface{}, error) {
+	if t.Kind() != reflect.String {
+		return nil, fmt.Errorf("default provider only support string, got %s", t)
+	}
+	return params, nil
+}
+
+func prepareKey(t reflect.Type, params string) (interface{}, error) {
+	if params == "" {
+		return nil, fmt.Errorf("default provider need a key")
+	}
+	return params, nil
+}
+
+func prepareNow(t reflect.Type, params string) (interface{}, error) {
+	if !isTime(t) {
+		return nil, fmt.Errorf("default provider now only support time, got %s", t)
+	}
+	if params == "" {
+		return time.Duration(0), nil
+	}
+	d, err := time.ParseDuration(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse offset %s, %s", params, err.Error())
+	}
+	return d, nil
+}
+
+// splitProvider 拆分 default tag 为 provider 名与参数，@now+24h 的参数为 +24h，不以 ProviderPrefix 开头时返回 ok=false
+func splitProvider(tag string) (string, string, bool) {
+	if !strings.HasPrefix(tag, ProviderPrefix) {
+		return "", "", false
+	}
+	tag = tag[len(ProviderPrefix):]
+	if strings.HasPrefix(tag, "now+") || strings.HasPrefix(tag, "now-") {
+		return "now", tag[3:], true
+	}
+	cols := strings.SplitN(tag, ":", 2)
+	if len(cols) > 1 {
+		return cols[0], cols[1], true
+	}
+	return cols[0], "", true
+}
+
+// providerValue 将 provider 返回的值转换为字段类型，无法转换时返回零值
+func providerValue(t reflect.Type, v interface{}) interface{} {
+	zero := reflect.New(t).Elem()
+	if v == nil {
+		return zero.Interface()
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return zero.Interface()
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type() == t {
+		return rv.Interface()
+	}
+	if rv.Kind() == reflect.String && t.Kind() != reflect.String {
+		parsed, err := parseString(t, rv.String())
+		if err != nil {
+			return zero.Interface()
+		}
+		return parsed
+	}
+	if t.Kind() == reflect.String {
+		return reflect.ValueOf(fmt.Sprint(rv.Interface())).Convert(t).Interface()
+	}
+	if rv.Type().ConvertibleTo(t) {
+		return rv.Convert(t).Interface()
+	}
+	return zero.Interface()
+}
+
+// parseString 将字符串解析为t类型，与 Field.Parse 一致
+func parseString(t reflect.Type, value string) (interface{}, error) {
+	ptr := reflect.New(t)
+	if unmarshaler, ok := ptr.Interface().(Unmarshaler); ok {
+		if err := unmarshaler.UnmarshalString(value); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	} else if isTime(t) {
+		tmp, err := now.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(tmp).Convert(t).Interface(), nil
+	}
+	v, err := parseValue(t, value)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("failed to parse %s for %s", value, t)
+	}
+	return v, nil
+}
+
+// NewUUID 生成随机 UUID（v4）
+func NewUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	s := hex.EncodeToString(b[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID 生成 ULID，前48位为毫秒时间戳，后80位随机，使用 Crockford Base32 编码为26位字符串
+func NewULID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	_, _ = rand.Read(b[6:])
+	// 128位按5位一组编码，首字符仅使用高3位
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = (lo >> 5) | (hi << 59)
+		hi >>= 5
+	}
+	return string(out)
+}
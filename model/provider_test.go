@@ -0,0 +1,125 @@
+package model
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful/field"
+)
+
+type ProviderCase struct {
+	ID       string           `default:"@uuid"`
+	Seq      string           `default:"@ulid"`
+	UserID   int64            `default:"@ctx:user_id"`
+	Owner    *string          `default:"@ctx:user_name"`
+	Tenant   string           `default:"@header:X-Tenant"`
+	Expire   field.Time       `default:"@now+24h"`
+	Before   *field.Timestamp `default:"@now-1h"`
+	Created  time.Time        `default:"@now"`
+	Literal  string           `default:"test2:777"`
+	Missing  int64            `default:"@ctx:missing"`
+	TenantID int              `default:"@header:X-Tenant-ID"`
+	// 不带 ProviderPrefix 时为字面值
+	Name  string `default:"uuid"`
+	State string `default:"now"`
+}
+
+func TestDefaultProvider(t *testing.T) {
+	m := NewModel(ProviderCase{})
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", nil)
+	c.Request.Header.Set("X-Tenant", "t1")
+	c.Request.Header.Set("X-Tenant-ID", "12")
+	c.Set("user_id", 7)
+	c.Set("user_name", "tom")
+
+	data := &ProviderCase{}
+	if err := m.ParseDefault(c, data, map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(data.ID) {
+		t.Errorf("uuid %s invalid", data.ID)
+	}
+	if !regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`).MatchString(data.Seq) {
+		t.Errorf("ulid %s invalid", data.Seq)
+	}
+	if data.UserID != 7 || data.Owner == nil || *data.Owner != "tom" {
+		t.Errorf("ctx default got %v %v", data.UserID, data.Owner)
+	}
+	if data.Tenant != "t1" || data.TenantID != 12 {
+		t.Errorf("header default got %s %d", data.Tenant, data.TenantID)
+	}
+	if d := time.Until(time.Time(data.Expire)); d < 23*time.Hour || d > 25*time.Hour {
+		t.Errorf("now+24h got %v", data.Expire)
+	}
+	if d := time.Since(time.Time(*data.Before)); d < 59*time.Minute || d > 61*time.Minute {
+		t.Errorf("now-1h got %v", data.Before)
+	}
+	if time.Since(data.Created) > time.Minute {
+		t.Errorf("now got %v", data.Created)
+	}
+	if data.Literal != "test2:777" || data.Missing != 0 {
+		t.Errorf("literal default got %s %d", data.Literal, data.Missing)
+	}
+	if data.Name != "uuid" || data.State != "now" {
+		t.Errorf("literal provider name default got %s %s", data.Name, data.State)
+	}
+
+	// 非 gin.Context 时取不到请求头
+	data = &ProviderCase{}
+	ctx := context.WithValue(context.Background(), "user_id", int64(9))
+	if err := m.ParseDefault(ctx, data, map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if data.UserID != 9 || data.Tenant != "" || data.Owner == nil || *data.Owner != "" {
+		t.Errorf("context default got %v %s %v", data.UserID, data.Tenant, data.Owner)
+	}
+	if NewULID() == NewULID() || NewUUID() == NewUUID() {
+		t.Errorf("generated id should be unique")
+	}
+}
+
+func TestDefaultProviderPanic(t *testing.T) {
+	cases := []interface{}{
+		struct {
+			ID int64 `default:"@uuid"`
+		}{},
+		struct {
+			Expire string `default:"@now+1x"`
+		}{},
+		struct {
+			Name string `default:"@ctx:"`
+		}{},
+		struct {
+			Num int64 `default:"@seq:order"`
+		}{},
+	}
+	for i, c := range cases {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("case %d should panic", i)
+				}
+			}()
+			NewModel(c)
+		}()
+	}
+
+	RegisterDefaultProvider("seq", func(t reflect.Type, params string) (interface{}, error) {
+		return params, nil
+	}, func(ctx context.Context, v interface{}) interface{} {
+		return 100
+	})
+	m := NewModel(struct {
+		Num int64 `default:"@seq:order"`
+	}{})
+	if v := m.Name2Field["Num"].GetDefaultValue(context.Background()); v != int64(100) {
+		t.Errorf("registered provider got %v", v)
+	}
+}
@@ -0,0 +1,45 @@
+package restful
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful/response"
+)
+
+// PanicLogger 记录请求处理中的 panic 与调用栈
+type PanicLogger func(c *gin.Context, err interface{}, stack []byte)
+
+// Recovery 请求处理中 panic 的处理方式，*response.Error 按原状态码返回，其余（含 runtime.Error）返回json格式的500
+type Recovery struct {
+	// Logger 默认使用标准库 log 输出
+	Logger PanicLogger
+	// RePanic 记录后重新 panic，交由上层（如 gin.Recovery）处理，用于开发环境调试
+	RePanic bool
+}
+
+var defaultRecovery = &Recovery{}
+
+func defaultPanicLogger(c *gin.Context, err interface{}, stack []byte) {
+	log.Printf("[restful] panic recovered, logid=%s %s %s: %v\n%s", c.GetString("logid"), c.Request.Method, c.Request.URL.Path, err, stack)
+}
+
+// Recover 记录 panic 并返回500错误，RePanic 时重新 panic
+func (rc *Recovery) Recover(c *gin.Context, r interface{}) *response.Error {
+	// 与 net/http 约定一致，中断响应的 panic 不做处理
+	if r == http.ErrAbortHandler {
+		panic(r)
+	}
+	logger := rc.Logger
+	if logger == nil {
+		logger = defaultPanicLogger
+	}
+	logger(c, r, debug.Stack())
+	if rc.RePanic {
+		panic(r)
+	}
+	return response.CodeInternal.New("")
+}
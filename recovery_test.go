@@ -0,0 +1,56 @@
+package restful
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful/response"
+)
+
+func TestRecovery(t *testing.T) {
+	demo := &DemoResource{Resource: NewResource(&DemoTable{})}
+	demo.RegisterMethod(ListMethod, HTTPMethodGet, "panic", func(c *gin.Context) Response {
+		var items []int
+		_ = items[len(c.Query("n"))]
+		return nil
+	})
+	app := gin.New()
+	app.Use(func(c *gin.Context) {
+		c.Set("logid", "123")
+	})
+	root := New()
+	var logged interface{}
+	var stack []byte
+	root.Recovery.Logger = func(c *gin.Context, err interface{}, s []byte) {
+		logged, stack = err, s
+	}
+	root.RegisterResource("/demo", demo)
+	root.Mount(app.Group("/api"))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/demo/panic", nil))
+	var res response.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	if w.Code != http.StatusInternalServerError || res.Code != "internal_error" || res.LogID != "123" {
+		t.Errorf("recover runtime error fail, code=%d body=%s", w.Code, w.Body.String())
+	}
+	if logged == nil || !strings.Contains(string(stack), "recovery_test.go") {
+		t.Errorf("recover logger fail, err=%v stack=%s", logged, stack)
+	}
+
+	// 开发环境重新 panic
+	root.Recovery.RePanic = true
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover RePanic should panic")
+			}
+		}()
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/demo/panic", nil))
+	}()
+}
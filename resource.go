@@ -1,8 +1,11 @@
 package restful
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/lookupearth/restful/event"
 	"github.com/lookupearth/restful/model"
 	"github.com/lookupearth/restful/response"
 	"gorm.io/gorm"
@@ -15,6 +18,18 @@ type Resource struct {
 	DB    *gorm.DB
 	Model *model.Model
 
+	// 可选
+	// Name 资源名，用于事件主题等，默认为表名
+	Name string
+	// Sinks 写操作事件接收方，在写事务内调用
+	Sinks []event.Sink
+	// LookupField 详情路由 :id 对应的json字段，如 slug，需为唯一字段，默认为主键
+	LookupField string
+	// Output 输出模型，未设置时直接输出 Model
+	Output *model.Output
+	// Versions 按接口版本设置的输入输出
+	Versions map[string]*ResourceVersion
+
 	// 方法设置
 	model     interface{}
 	instance  interface{}
@@ -22,11 +37,24 @@ type Resource struct {
 	root      IRoot
 }
 
+var (
+	_ IResourceName   = (*Resource)(nil)
+	_ IResourceOutput = (*Resource)(nil)
+	_ IResourceLookup = (*Resource)(nil)
+)
+
 func NewResource(m IModel) *Resource {
+	return NewResourceWithDB(m.Database(), m)
+}
+
+// NewResourceWithDB 使用指定 DB 创建 Resource，适用于 model 未实现 IModel 的场景
+func NewResourceWithDB(db *gorm.DB, m interface{}) *Resource {
+	resourceModel := model.NewModelWithDB(db, m)
 	return &Resource{
 		Controller: NewController(),
-		DB:         m.Database(),
-		Model:      model.NewModel(m),
+		DB:         db,
+		Model:      resourceModel,
+		Name:       resourceModel.TableName(),
 	}
 }
 
@@ -42,6 +70,16 @@ func (resource *Resource) Init(instance interface{}, root IRoot) {
 			panic("model need a PrimaryKey")
 		}
 	}
+	if len(resource.LookupField) > 0 {
+		name, ok := resource.Model.Json2Name[resource.LookupField]
+		if !ok || len(resource.Model.Name2Field[name].DBKey) == 0 {
+			panic(fmt.Sprintf("lookup field <%s> need a gorm column", resource.LookupField))
+		}
+	}
+}
+
+func (resource *Resource) GetName() string {
+	return resource.Name
 }
 
 func (resource *Resource) GetDB() *gorm.DB {
@@ -64,19 +102,143 @@ func (resource *Resource) Query() *gorm.DB {
 	return resource.DB.Model(resource.Model.New())
 }
 
+// QueryWithContext 获取绑定了请求 context 的查询句柄，见 DBContext
 func (resource *Resource) QueryWithContext(ctx *gin.Context) *gorm.DB {
-	return resource.DB.Model(resource.Model.New()).WithContext(ctx)
+	return resource.DB.Model(resource.Model.New()).WithContext(DBContext(ctx))
 }
 
 func (resource *Resource) QueryPrimaryKey(c *gin.Context) *gorm.DB {
-	primaryKey := resource.GetPrimaryKey(c)
-	return resource.QueryWithContext(c).Where(resource.Model.PrimaryKey+" = ?", primaryKey)
+	columns := resource.lookupColumns()
+	return resource.Model.WhereKey(resource.QueryWithContext(c), columns, resource.GetLookup(c))
+}
+
+// lookupColumns 详情路由 :id 对应的db列
+func (resource *Resource) lookupColumns() []string {
+	if len(resource.LookupField) > 0 {
+		name := resource.Model.Json2Name[resource.LookupField]
+		return []string{resource.Model.Name2Column[name]}
+	}
+	return resource.Model.PrimaryKeys
+}
+
+// SetOutputModel 设置输出模型，返回数据按json字段映射为该结构体
+func (resource *Resource) SetOutputModel(output interface{}) {
+	resource.Output = model.NewOutput(output)
+}
+
+// Version 获取指定接口版本的设置，不存在时创建
+func (resource *Resource) Version(version string) *ResourceVersion {
+	if resource.Versions == nil {
+		resource.Versions = make(map[string]*ResourceVersion)
+	}
+	v, ok := resource.Versions[version]
+	if !ok {
+		v = &ResourceVersion{}
+		resource.Versions[version] = v
+	}
+	return v
+}
+
+// SetVersionInputModel 设置指定接口版本的输入模型
+func (resource *Resource) SetVersionInputModel(version string, input interface{}) {
+	resource.Version(version).Input = model.NewOutput(input)
+}
+
+// SetVersionOutputModel 设置指定接口版本的输出模型
+func (resource *Resource) SetVersionOutputModel(version string, output interface{}) {
+	resource.Version(version).Output = model.NewOutput(output)
+}
+
+// GetOutput 获取当前请求版本的输出模型，未设置时返回nil
+func (resource *Resource) GetOutput(c *gin.Context) *model.Output {
+	if v, ok := resource.Versions[VersionFromContext(c)]; ok && v.Output != nil {
+		return v.Output
+	}
+	return resource.Output
+}
+
+// TransformRequest 将当前请求版本的请求数据转换为 Model 的json数据
+func (resource *Resource) TransformRequest(c *gin.Context, body []byte) ([]byte, error) {
+	if v, ok := resource.Versions[VersionFromContext(c)]; ok {
+		return v.transformRequest(c, body)
+	}
+	return body, nil
+}
+
+// TransformResponse 按当前请求版本转换返回数据
+func (resource *Resource) TransformResponse(c *gin.Context, data interface{}) (interface{}, error) {
+	if v, ok := resource.Versions[VersionFromContext(c)]; ok {
+		return v.transformResponse(c, data)
+	}
+	return data, nil
+}
+
+// AddSink 添加写操作事件接收方
+func (resource *Resource) AddSink(sinks ...event.Sink) {
+	resource.Sinks = append(resource.Sinks, sinks...)
+}
+
+// EmitEvent 在写事务 tx 内发出事件，任一 Sink 失败即返回错误
+func (resource *Resource) EmitEvent(tx *gorm.DB, e *event.Event) error {
+	if len(e.Resource) == 0 {
+		e.Resource = resource.Name
+	}
+	for _, sink := range resource.Sinks {
+		if err := sink.Emit(tx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyEvent 写事务提交后通知实现了 event.Notifier 的 Sink
+func (resource *Resource) NotifyEvent(e *event.Event) {
+	for _, sink := range resource.Sinks {
+		if notifier, ok := sink.(event.Notifier); ok {
+			notifier.Notify(e)
+		}
+	}
 }
 
+// GetLookup 解析详情路由的 :id，返回db列到值的映射，联合主键格式见 model.ParseKey
+func (resource *Resource) GetLookup(c *gin.Context) map[string]interface{} {
+	key, err := resource.Model.ParseKey(c.Param("id"), resource.lookupColumns())
+	if err != nil {
+		panic(response.CodeNotFound.Wrap(err))
+	}
+	return key
+}
+
+// GetPrimaryKey 获取url中的主键，联合主键时为db列到值的映射，设置 LookupField 时为查找字段的值
 func (resource *Resource) GetPrimaryKey(c *gin.Context) interface{} {
-	primaryKey, err := resource.Model.ParsePrimaryKey(c.Param(":id"))
+	return resource.Model.KeyValue(resource.GetLookup(c))
+}
+
+// ResourceName 获取资源名，未实现 IResourceName 时为 model 的表名
+func ResourceName(resource IResource) string {
+	if r, ok := resource.(IResourceName); ok {
+		return r.GetName()
+	}
+	return resource.GetModel().TableName()
+}
+
+// ResourceOutput 获取当前请求的输出模型，未实现 IResourceOutput 或未设置时返回nil
+func ResourceOutput(resource IResource, c *gin.Context) *model.Output {
+	if r, ok := resource.(IResourceOutput); ok {
+		return r.GetOutput(c)
+	}
+	return nil
+}
+
+// ResourceLookup 获取详情路由定位数据的db列与值，未实现 IResourceLookup 时按主键解析 :id
+func ResourceLookup(resource IResource, c *gin.Context) map[string]interface{} {
+	if r, ok := resource.(IResourceLookup); ok {
+		return r.GetLookup(c)
+	}
+	m := resource.GetModel()
+	key, err := m.ParseKey(c.Param("id"), m.PrimaryKeys)
 	if err != nil {
-		panic(response.NewError(404, err))
+		panic(response.CodeNotFound.Wrap(err))
 	}
-	return primaryKey
+	return key
 }
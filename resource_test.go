@@ -0,0 +1,48 @@
+package restful
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful/model"
+)
+
+// customResource 未嵌入 Resource 的 IResource 实现，只实现必需的方法
+type customResource struct {
+	IResource
+	model *model.Model
+}
+
+func (r *customResource) GetModel() *model.Model {
+	return r.model
+}
+
+func TestResourceOptional(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+	custom := &customResource{model: model.NewModel(&UniqueUser{})}
+	if name := ResourceName(custom); name != "unique_users" {
+		t.Errorf("ResourceName fallback fail, got=%s", name)
+	}
+	if out := ResourceOutput(custom, c); out != nil {
+		t.Errorf("ResourceOutput fallback fail, got=%v", out)
+	}
+	if lookup := ResourceLookup(custom, c); !reflect.DeepEqual(lookup, map[string]interface{}{"id": int64(5)}) {
+		t.Errorf("ResourceLookup fallback fail, got=%v", lookup)
+	}
+
+	resource := NewResourceWithDB(dryRunDB(t), &UniqueUser{})
+	resource.Name = "users"
+	resource.LookupField = "email"
+	c.Params = gin.Params{{Key: "id", Value: "a@b.c"}}
+	if name := ResourceName(resource); name != "users" {
+		t.Errorf("ResourceName fail, got=%s", name)
+	}
+	if lookup := ResourceLookup(resource, c); !reflect.DeepEqual(lookup, map[string]interface{}{"email": "a@b.c"}) {
+		t.Errorf("ResourceLookup fail, got=%v", lookup)
+	}
+}
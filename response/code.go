@@ -0,0 +1,117 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ErrorCode 已知的错误码，同一服务内 Code 唯一
+type ErrorCode struct {
+	Code string `json:"code"`
+	// HTTPStatus http 状态码，同时作为 Error.Status
+	HTTPStatus int `json:"httpStatus"`
+	// Msg 默认错误信息
+	Msg string `json:"msg"`
+}
+
+var (
+	codeLock sync.RWMutex
+	codes    = make(map[string]*ErrorCode)
+	// statusCodes http 状态码对应的默认错误码，NewErrorFromMsg 使用
+	statusCodes = make(map[int]*ErrorCode)
+)
+
+// 内置错误码，mixins 中统一使用
+var (
+	CodeBadRequest         = registerDefault("bad_request", http.StatusBadRequest, "bad request")
+	CodeValidationFailed   = RegisterErrorCode("validation_failed", http.StatusBadRequest, "validation failed")
+	CodeUnauthorized       = registerDefault("unauthorized", http.StatusUnauthorized, "unauthorized")
+	CodeForbidden          = registerDefault("forbidden", http.StatusForbidden, "forbidden")
+	CodeNotFound           = registerDefault("not_found", http.StatusNotFound, "not found")
+	CodeMethodNotAllowed   = registerDefault("method_not_allowed", http.StatusMethodNotAllowed, "Method Not Allowed")
+	CodeNotAcceptable      = registerDefault("not_acceptable", http.StatusNotAcceptable, "not acceptable")
+	CodeConflict           = registerDefault("conflict", http.StatusConflict, "conflict")
+	CodePreconditionFailed = registerDefault("precondition_failed", http.StatusPreconditionFailed, "precondition failed")
+	CodeUnprocessable      = registerDefault("unprocessable_entity", http.StatusUnprocessableEntity, "unprocessable entity")
+	CodeTooManyRequests    = registerDefault("too_many_requests", http.StatusTooManyRequests, "too many requests")
+	CodeInternal           = registerDefault("internal_error", http.StatusInternalServerError, "internal error")
+	CodeUnavailable        = registerDefault("service_unavailable", http.StatusServiceUnavailable, "service unavailable")
+)
+
+// RegisterErrorCode 注册错误码，确保仅在启动阶段调用，Code 重复时 panic
+func RegisterErrorCode(code string, httpStatus int, msg string) *ErrorCode {
+	codeLock.Lock()
+	defer codeLock.Unlock()
+	if _, ok := codes[code]; ok {
+		panic(fmt.Sprintf("error code <%s> already registered", code))
+	}
+	c := &ErrorCode{
+		Code:       code,
+		HTTPStatus: httpStatus,
+		Msg:        msg,
+	}
+	codes[code] = c
+	return c
+}
+
+func registerDefault(code string, httpStatus int, msg string) *ErrorCode {
+	c := RegisterErrorCode(code, httpStatus, msg)
+	statusCodes[httpStatus] = c
+	return c
+}
+
+func statusCode(status int) *ErrorCode {
+	codeLock.RLock()
+	defer codeLock.RUnlock()
+	return statusCodes[status]
+}
+
+// GetErrorCode 获取已注册的错误码，不存在时返回nil
+func GetErrorCode(code string) *ErrorCode {
+	codeLock.RLock()
+	defer codeLock.RUnlock()
+	return codes[code]
+}
+
+// ErrorCodes 全部已注册的错误码，按 Code 排序，可用于生成文档
+func ErrorCodes() []*ErrorCode {
+	codeLock.RLock()
+	defer codeLock.RUnlock()
+	list := make([]*ErrorCode, 0, len(codes))
+	for _, c := range codes {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
+// New 创建该错误码的错误，msg 为空时使用默认错误信息
+func (c *ErrorCode) New(msg string) *Error {
+	if msg == "" {
+		msg = c.Msg
+	}
+	return &Error{
+		Msg:    msg,
+		Status: c.HTTPStatus,
+		Code:   c.Code,
+	}
+}
+
+// Wrap 使用 err 的信息创建该错误码的错误，错误链中存在 *Error 时沿用，见 AsError
+func (c *ErrorCode) Wrap(err error) *Error {
+	if e := AsError(err); e != nil {
+		return e
+	}
+	e := c.New(err.Error())
+	e.cause = err
+	return e
+}
+
+// Error 实现 error，用于 errors.Is 判断错误码
+func (c *ErrorCode) Error() string {
+	return c.Code
+}
@@ -1,6 +1,8 @@
 package response
 
 import (
+	"errors"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -8,24 +10,58 @@ type Error struct {
 	Msg    string
 	Status int
 	Data   interface{}
+	// Code 稳定的错误码，如 not_found，见 RegisterErrorCode
+	Code string
+	// HTTPStatus 可选，指定 http 状态码，为0时根据 Status 推断，用于业务状态码与 http 状态码不同的场景
+	HTTPStatus int
+	// Details 可选的详细信息，如字段校验错误
+	Details interface{}
+	LogID   string
+
+	// cause 原始错误，见 Unwrap
+	cause error
 }
 
+// NewError 将 err 转换为 Error，错误链中存在 *Error 时（如 fmt.Errorf("...: %w", e)）沿用其状态码与错误码
 func NewError(status int, err error) *Error {
-	err2, ok := err.(*Error)
-	if ok && err2 != nil {
-		return err2
+	if e := AsError(err); e != nil {
+		return e
 	}
-	return NewErrorFromMsg(status, err.Error())
+	e := NewErrorFromMsg(status, err.Error())
+	e.cause = err
+	return e
 }
 
+// AsError 获取错误链中的 *Error，err 本身为 *Error 时原样返回，被包装时错误信息使用完整的 err.Error()
+//
+//	不存在时返回nil
+func AsError(err error) *Error {
+	var e *Error
+	if !errors.As(err, &e) || e == nil {
+		return nil
+	}
+	if e == err {
+		return e
+	}
+	wrapped := *e
+	wrapped.Msg = err.Error()
+	wrapped.cause = err
+	return &wrapped
+}
+
+// NewErrorFromMsg 根据状态码创建错误，Code 默认为该状态码对应的内置错误码
 func NewErrorFromMsg(status int, msg string) *Error {
 	if status == 0 {
 		status = 1
 	}
-	return &Error{
+	e := &Error{
 		Msg:    msg,
 		Status: status,
 	}
+	if code := statusCode(status); code != nil {
+		e.Code = code.Code
+	}
+	return e
 }
 
 func (e *Error) Error() string {
@@ -40,11 +76,53 @@ func (e *Error) GetData() interface{} {
 	return e.Data
 }
 
+// Unwrap 返回原始错误，支持 errors.Is/errors.As
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is 错误码相同（均无错误码时状态码相同）时视为同一错误，target 可以是 *Error 或 *ErrorCode
+//
+//	如 errors.Is(err, response.CodeNotFound)
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case *ErrorCode:
+		return e.Code == t.Code
+	case *Error:
+		if t.Code != "" || e.Code != "" {
+			return e.Code == t.Code
+		}
+		return e.Status == t.Status
+	}
+	return false
+}
+
+// WithCause 设置原始错误，不影响返回给客户端的错误信息，可通过 errors.Unwrap 获取用于记录日志
+func (e *Error) WithCause(err error) *Error {
+	e.cause = err
+	return e
+}
+
+// SetLogID 为 Error 设置 LogID
+func (e *Error) SetLogID(logid string) {
+	e.LogID = logid
+}
+
+// WithDetails 设置详细信息
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
 func (e *Error) Response(c *gin.Context) {
 	res := &Response{
-		Status: e.GetStatus(),
-		Msg:    e.Error(),
-		Data:   e.GetData(),
+		Status:     e.GetStatus(),
+		Msg:        e.Error(),
+		Data:       e.GetData(),
+		Code:       e.Code,
+		Details:    e.Details,
+		HTTPStatus: e.HTTPStatus,
+		LogID:      e.LogID,
 	}
 	res.Response(c)
 }
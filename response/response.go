@@ -14,11 +14,19 @@ type Response struct {
 	Total  *int64      `json:"total,omitempty"`
 	From   string      `json:"from,omitempty"`
 	LogID  string      `json:"logid,omitempty"`
+	// Code 错误码，见 ErrorCode
+	Code    string      `json:"code,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+
+	// HTTPStatus 可选，指定 http 状态码，如201，为0时根据 Status 推断
+	HTTPStatus int `json:"-"`
 }
 
 func (response *Response) Response(c *gin.Context) {
 	var httpCode int
-	if response.Status == 0 {
+	if response.HTTPStatus != 0 {
+		httpCode = response.HTTPStatus
+	} else if response.Status == 0 {
 		httpCode = 200
 	} else if response.Status >= 400 && response.Status <= 599 {
 		httpCode = response.Status
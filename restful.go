@@ -1,34 +1,168 @@
 package restful
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 type restful struct {
 	Validator *Validator
+	// DefaultVersion 未通过 Accept-Version 指定版本时使用的版本，默认为第一个添加的版本，保证老客户端不受影响
+	DefaultVersion string
+	// Recovery 请求处理中 panic 的处理方式
+	Recovery *Recovery
+
 	resources map[string]IController
+	versions  []*Version
+	// versionResources 版本单独注册的资源，key 为版本名、资源url
+	versionResources map[string]map[string]IController
 }
 
+var (
+	_ IVersionRoot  = (*restful)(nil)
+	_ IRecoveryRoot = (*restful)(nil)
+)
+
 func New() *restful {
+	v := &Validator{
+		Validator: validator.New(),
+	}
+	// 注册 unique、exists 等查询数据库的校验
+	v.RegisterDBValidations()
 	return &restful{
-		Validator: &Validator{
-			Validator: validator.New(),
-		},
-		resources: make(map[string]IController),
+		Validator:        v,
+		Recovery:         &Recovery{},
+		resources:        make(map[string]IController),
+		versionResources: make(map[string]map[string]IController),
 	}
 }
 
-// RegisterResource 注册资源
+// RegisterResource 注册资源，设置了版本时在全部版本中可用
 func (r *restful) RegisterResource(url string, ctrl IController) {
 	ctrl.Init(ctrl, r)
 	r.resources[url] = ctrl
 }
 
+// AddVersion 添加接口版本，版本名不能重复
+func (r *restful) AddVersion(versions ...*Version) {
+	for _, v := range versions {
+		if v.Name == "" || strings.Contains(v.Name, "/") {
+			panic(fmt.Sprintf("invalid version name <%s>", v.Name))
+		}
+		if r.getVersion(v.Name) != nil {
+			panic(fmt.Sprintf("version <%s> conflict", v.Name))
+		}
+		r.versions = append(r.versions, v)
+	}
+}
+
+// RegisterVersionResource 注册指定版本的资源，覆盖该版本下 RegisterResource 注册的同url资源
+func (r *restful) RegisterVersionResource(version string, url string, ctrl IController) {
+	if r.getVersion(version) == nil {
+		panic(fmt.Sprintf("version <%s> not found", version))
+	}
+	ctrl.Init(ctrl, r)
+	if _, ok := r.versionResources[version]; !ok {
+		r.versionResources[version] = make(map[string]IController)
+	}
+	r.versionResources[version][url] = ctrl
+}
+
+// Versions 获取全部接口版本
+func (r *restful) Versions() []*Version {
+	return r.versions
+}
+
+func (r *restful) getVersion(name string) *Version {
+	for _, v := range r.versions {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (r *restful) defaultVersion() string {
+	if r.DefaultVersion != "" || len(r.versions) == 0 {
+		return r.DefaultVersion
+	}
+	return r.versions[0].Name
+}
+
+// versionController 获取版本下的资源
+func (r *restful) versionController(version string, url string) IController {
+	if ctrl, ok := r.versionResources[version][url]; ok {
+		return ctrl
+	}
+	return r.resources[url]
+}
+
+// urls 全部资源url
+func (r *restful) urls() []string {
+	urls := make([]string, 0, len(r.resources))
+	for url := range r.resources {
+		urls = append(urls, url)
+	}
+	for _, resources := range r.versionResources {
+		for url := range resources {
+			if _, ok := r.resources[url]; !ok && !containsURL(urls, url) {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+func containsURL(urls []string, url string) bool {
+	for _, u := range urls {
+		if u == url {
+			return true
+		}
+	}
+	return false
+}
+
 // Mount 挂载全部controller
+//
+//	设置了版本时，资源挂载到 /<version>/<url>，并在 <url> 上根据 Accept-Version 请求头选择版本，controller 需实现 IRoutes
 func (r *restful) Mount(router *gin.RouterGroup) {
-	for url, ctrl := range r.resources {
-		ctrl.Mount(router, url)
+	if len(r.versions) == 0 {
+		for url, ctrl := range r.resources {
+			ctrl.Mount(router, url)
+		}
+		return
+	}
+	defaultVersion := r.defaultVersion()
+	if r.getVersion(defaultVersion) == nil {
+		panic(fmt.Sprintf("default version <%s> not found", defaultVersion))
+	}
+	for _, url := range r.urls() {
+		// path -> version -> handler
+		negotiation := make(map[string]map[string]gin.HandlerFunc)
+		for _, v := range r.versions {
+			ctrl := r.versionController(v.Name, url)
+			if ctrl == nil {
+				continue
+			}
+			routes, ok := ctrl.(IRoutes)
+			if !ok {
+				panic(fmt.Sprintf("controller of <%s> should implement IRoutes to support versions", url))
+			}
+			for path, handler := range routes.Routes() {
+				handler = versionHandler(v, handler)
+				router.Any("/"+v.Name+url+path, handler)
+				if _, ok := negotiation[path]; !ok {
+					negotiation[path] = make(map[string]gin.HandlerFunc)
+				}
+				negotiation[path][v.Name] = handler
+			}
+		}
+		for path, handlers := range negotiation {
+			router.Any(url+path, negotiateHandler(defaultVersion, handlers))
+		}
 	}
 }
 
@@ -37,13 +171,33 @@ func (r *restful) GetValidator() IValidator {
 	return r.Validator
 }
 
+// GetRecovery 获取 panic 的处理方式
+func (r *restful) GetRecovery() *Recovery {
+	return r.Recovery
+}
+
 // Validate 获取 *validator.Validate，用于注册自定义校验函数
 func (r *restful) Validate() *validator.Validate {
 	return r.Validator.Validator
 }
 
 func (r *restful) Print(prefix string) {
-	for url, ctrl := range r.resources {
-		ctrl.Print(prefix + url)
+	if len(r.versions) == 0 {
+		for url, ctrl := range r.resources {
+			ctrl.Print(prefix + url)
+		}
+		return
+	}
+	for _, url := range r.urls() {
+		versions := make([]string, 0, len(r.versions))
+		for _, v := range r.versions {
+			ctrl := r.versionController(v.Name, url)
+			if ctrl == nil {
+				continue
+			}
+			versions = append(versions, v.String())
+			ctrl.Print(prefix + "/" + v.Name + url)
+		}
+		fmt.Printf("%s %s [%s], default %s\n", VersionHeader, prefix+url, strings.Join(versions, ", "), r.defaultVersion())
 	}
 }
@@ -1,9 +1,11 @@
 package restful
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"net/http"
 	"reflect"
 
 	"github.com/lookupearth/restful/model"
@@ -11,6 +13,7 @@ import (
 )
 
 type Serializer struct {
+	ctx          *gin.Context
 	model        *model.Model
 	validator    IValidator
 	partial      bool
@@ -36,13 +39,13 @@ func (s *Serializer) WithDefaults(keys []string) ISerializer {
 	return s
 }
 
-// setRawData 设置原始数据，过滤readonly部分
+// setRawData 设置原始数据，过滤readonly与自动维护的部分
 func (s *Serializer) setRawData(input map[string]interface{}) {
 	rawData := make(map[string]interface{})
-	for k, v := range input {
+	for k, v := range s.model.FlattenNested(input) {
 		if name, ok := s.model.Json2Name[k]; ok {
 			field := s.model.Name2Field[name]
-			if field.ReadOnly() {
+			if field.ReadOnly() || field.IsAuto() {
 				continue
 			}
 		}
@@ -57,6 +60,7 @@ func (s *Serializer) Parse(c *gin.Context, b []byte) error {
 	if err != nil {
 		return err
 	}
+	s.ctx = c
 	s.structData = data
 	var rawData map[string]interface{}
 	if err := json.Unmarshal(b, &rawData); err != nil {
@@ -83,6 +87,7 @@ func (s *Serializer) ParseFromQuery(c *gin.Context, query map[string]string) err
 	if err != nil {
 		return err
 	}
+	s.ctx = c
 	s.structData = data
 	rawData := make(map[string]interface{})
 	for k, v := range query {
@@ -122,6 +127,11 @@ func (s *Serializer) Validate(c *gin.Context) *response.Error {
 		if s.partial == false {
 			return s.validator.Validate(c, s.structData)
 		} else {
+			// 记录传入的数据，unique_together 中未传入的字段使用当前数据的值
+			if c != nil {
+				c.Set(ctxPartialData, s.rawData)
+				defer c.Set(ctxPartialData, map[string]interface{}(nil))
+			}
 			return s.validator.ValidatePartial(c, s.structData, s.model.FieldNames(s.rawData))
 		}
 	}
@@ -132,6 +142,7 @@ func (s *Serializer) Validate(c *gin.Context) *response.Error {
 // 否则只能返回两个参数，不便于调用
 // 该函数返回的map，key为数据库列，不一定是json/form的key
 // 注意，该方法应仅在需要存储数据到db时使用，参数解析务必使用 JsonData 方法
+// 自动维护的字段（见 model.AutoData）会被加入，POST 时包含创建字段，其余请求只包含更新字段
 func (s *Serializer) ValidateData() map[string]interface{} {
 	validateData := make(map[string]interface{})
 	if !s.structValue.IsValid() {
@@ -142,7 +153,7 @@ func (s *Serializer) ValidateData() map[string]interface{} {
 		if !ok {
 			continue
 		}
-		fv := s.structValue.FieldByName(name)
+		fv := s.model.FieldValue(s.structValue, name)
 		if jsonKey, ok := s.model.Name2Json[name]; ok {
 			if _, ok := s.rawData[jsonKey]; ok {
 				validateData[column] = fv.Interface()
@@ -150,6 +161,15 @@ func (s *Serializer) ValidateData() map[string]interface{} {
 			}
 		}
 	}
+	var ctx context.Context
+	create := false
+	if s.ctx != nil {
+		ctx = s.ctx
+		create = s.ctx.Request != nil && s.ctx.Request.Method == http.MethodPost
+	}
+	for column, value := range s.model.AutoData(ctx, create) {
+		validateData[column] = value
+	}
 	return validateData
 }
 
@@ -162,7 +182,7 @@ func (s *Serializer) GetWithDefault(key string, value interface{}) interface{} {
 	}
 	if _, ok := s.rawData[key]; ok {
 		if name, ok := s.model.Json2Name[key]; ok {
-			fv := s.structValue.FieldByName(name)
+			fv := s.model.FieldValue(s.structValue, name)
 			return fv.Interface()
 		}
 	}
@@ -178,7 +198,7 @@ func (s *Serializer) Get(key string) (interface{}, error) {
 	}
 	if _, ok := s.rawData[key]; ok {
 		if name, ok := s.model.Json2Name[key]; ok {
-			fv := s.structValue.FieldByName(name)
+			fv := s.model.FieldValue(s.structValue, name)
 			return fv.Interface(), nil
 		}
 	}
@@ -198,7 +218,7 @@ func (s *Serializer) JsonData() map[string]interface{} {
 		if !ok {
 			continue
 		}
-		fv := s.structValue.FieldByName(name)
+		fv := s.model.FieldValue(s.structValue, name)
 		if _, ok := s.rawData[jsonKey]; ok {
 			jsonData[jsonKey] = fv.Interface()
 			continue
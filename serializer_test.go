@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	valid "github.com/go-playground/validator/v10"
 	"github.com/lookupearth/restful/model"
@@ -154,3 +155,96 @@ func TestPartial(t *testing.T) {
 		t.Errorf("Serializer.GetWithDefault fail, expect=%v got=%v", 2, v3i)
 	}
 }
+
+type EmbeddedBase struct {
+	ID   int64  `gorm:"column:id;primaryKey" json:"id"`
+	Kind string `gorm:"column:kind" json:"kind" validate:"required"`
+}
+
+type EmbeddedAuthor struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type EmbeddedActivity struct {
+	*EmbeddedBase
+	Title  string         `gorm:"column:title" json:"title"`
+	Author EmbeddedAuthor `gorm:"embedded;embeddedPrefix:author_" json:"author"`
+}
+
+func TestSerializerEmbedded(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	s := newSerializer(&EmbeddedActivity{}, false)
+	if err := s.Parse(ctx, []byte(`{"id":1,"kind":"k","author":{"name":"a"}}`)); err != nil {
+		t.Fatalf("Serializer.Parse fail, error=%v", err)
+	}
+	if err := s.Validate(ctx); err != nil {
+		t.Errorf("Serializer.Validate fail, error=%v", err)
+	}
+	expected := map[string]interface{}{"id": int64(1), "kind": "k", "author_name": "a"}
+	if !reflect.DeepEqual(s.ValidateData(), expected) {
+		t.Errorf("Serializer.ValidateData fail, got=%v", s.ValidateData())
+	}
+
+	// 部分校验使用完整结构体路径
+	s = newSerializer(&EmbeddedActivity{}, true)
+	if err := s.Parse(ctx, []byte(`{"kind":"","title":"t"}`)); err != nil {
+		t.Fatalf("Serializer.Parse fail, error=%v", err)
+	}
+	if err := s.Validate(ctx); err == nil {
+		t.Errorf("Serializer.Validate partial embedded should fail")
+	}
+	s = newSerializer(&EmbeddedActivity{}, true)
+	if err := s.Parse(ctx, []byte(`{"title":"t"}`)); err != nil {
+		t.Fatalf("Serializer.Parse fail, error=%v", err)
+	}
+	if err := s.Validate(ctx); err != nil {
+		t.Errorf("Serializer.Validate partial fail, error=%v", err)
+	}
+	if !reflect.DeepEqual(s.ValidateData(), map[string]interface{}{"title": "t"}) {
+		t.Errorf("Serializer.ValidateData partial fail, got=%v", s.ValidateData())
+	}
+}
+
+type AutoActivity struct {
+	ID        int64      `gorm:"column:id;primaryKey" json:"id"`
+	Name      string     `gorm:"column:name" json:"name"`
+	CreatedAt field.Time `gorm:"column:create_time" json:"create_time" auto:"create_time"`
+	UpdatedAt int64      `gorm:"column:update_time" json:"update_time" auto:"update_time"`
+	Creator   string     `gorm:"column:creator" json:"creator" auto:"create_user"`
+	Updater   int64      `gorm:"column:updater" json:"updater" auto:"update_user"`
+}
+
+func TestSerializerAuto(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("POST", "/activity", nil)
+	ctx.Set(model.AutoUserKey, 12)
+	s := newSerializer(&AutoActivity{}, false)
+	body := []byte(`{"name":"a","create_time":"2000-01-01 00:00:00","creator":"x","updater":1}`)
+	if err := s.Parse(ctx, body); err != nil {
+		t.Fatalf("Serializer.Parse fail, error=%v", err)
+	}
+	data := s.ValidateData()
+	if created, ok := data["create_time"].(field.Time); !ok || time.Since(time.Time(created)) > time.Minute {
+		t.Errorf("Serializer.ValidateData create_time fail, got=%v", data["create_time"])
+	}
+	if updated, ok := data["update_time"].(int64); !ok || time.Now().Unix()-updated > 60 {
+		t.Errorf("Serializer.ValidateData update_time fail, got=%v", data["update_time"])
+	}
+	if data["creator"] != "12" || data["updater"] != int64(12) || data["name"] != "a" {
+		t.Errorf("Serializer.ValidateData user fail, got=%v", data)
+	}
+
+	// 更新时只设置更新字段
+	ctx.Request = httptest.NewRequest("PATCH", "/activity/1", nil)
+	s = newSerializer(&AutoActivity{}, true)
+	if err := s.Parse(ctx, []byte(`{"update_time":1}`)); err != nil {
+		t.Fatalf("Serializer.Parse fail, error=%v", err)
+	}
+	data = s.ValidateData()
+	if _, ok := data["create_time"]; ok {
+		t.Errorf("Serializer.ValidateData update should not set create_time")
+	}
+	if _, ok := data["creator"]; ok || len(data) != 2 || data["update_time"] == int64(1) {
+		t.Errorf("Serializer.ValidateData update fail, got=%v", data)
+	}
+}
@@ -19,15 +19,26 @@ func GetQuery(c *gin.Context) map[string]string {
 	return params
 }
 
+// CheckDBResult 数据库操作失败时 panic，唯一键、外键等可识别的错误转换为对应的状态码，见 NewDBError
 func CheckDBResult(result *gorm.DB) {
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		panic(response.NewError(404, result.Error))
+		panic(response.CodeNotFound.Wrap(result.Error))
 	}
 	if result.Error != nil {
-		panic(response.NewError(500, result.Error))
+		panic(NewDBResultError(result))
 	}
 }
 
+// NewDBResultError 数据库操作失败时返回的错误，可识别的错误见 NewDBError，其余为500
+//
+//	用于事务提交等不便 panic 的场景；500 不返回原始的数据库错误信息，原始错误通过 errors.Unwrap 获取
+func NewDBResultError(result *gorm.DB) *response.Error {
+	if err := NewDBError(result); err != nil {
+		return err
+	}
+	return response.CodeInternal.New("").WithCause(result.Error)
+}
+
 func InstallDecorators(handler HandlerFunc, decorators []HandlerDecorator) HandlerFunc {
 	if decorators != nil {
 		for _, decorator := range decorators {
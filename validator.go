@@ -2,9 +2,12 @@ package restful
 
 import (
 	"context"
+	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 
+	"github.com/lookupearth/restful/model"
 	"github.com/lookupearth/restful/response"
 )
 
@@ -16,6 +19,13 @@ type ModelValidator interface {
 	Validate(validator.StructLevel)
 }
 
+// FieldError 校验失败的字段，作为 validation_failed 错误的 Details 返回，Field 为json字段
+type FieldError struct {
+	Field string `json:"field"`
+	Tag   string `json:"tag"`
+	Param string `json:"param,omitempty"`
+}
+
 type Validator struct {
 	Validator *validator.Validate
 }
@@ -32,21 +42,61 @@ func (v *Validator) Register(model interface{}) {
 }
 
 func (v *Validator) Validate(ctx context.Context, data interface{}) *response.Error {
-	return v.convert(v.Validator.StructCtx(ctx, data))
+	return v.convert(data, v.Validator.StructCtx(ctx, data))
 }
 
 func (v *Validator) ValidatePartial(ctx context.Context, data interface{}, fields []string) *response.Error {
-	return v.convert(v.Validator.StructPartialCtx(ctx, data, fields...))
+	return v.convert(data, v.Validator.StructPartialCtx(ctx, data, fields...))
 }
 
-func (v *Validator) convert(err error) *response.Error {
+func (v *Validator) convert(data interface{}, err error) *response.Error {
 	if err != nil {
 		if _, ok := err.(*validator.InvalidValidationError); ok {
 			return response.NewError(500, err)
 		}
-		for _, err := range err.(validator.ValidationErrors) {
-			return response.NewError(400, err)
+		errs := err.(validator.ValidationErrors)
+		details := make([]*FieldError, 0, len(errs))
+		for _, fe := range errs {
+			details = append(details, &FieldError{Field: jsonField(reflect.TypeOf(data), fe), Tag: fe.Tag(), Param: fe.Param()})
 		}
+		// 错误信息保持为第一个校验错误
+		return response.CodeValidationFailed.New(errs[0].Error()).WithDetails(details)
 	}
 	return nil
 }
+
+// jsonField 将校验错误的字段转换为json字段，与 model.Model 一致：匿名嵌入的字段提升到上一层，具名嵌入的为 <key>.<子字段>
+//
+//	无法解析时返回结构体字段名
+func jsonField(t reflect.Type, fe validator.FieldError) string {
+	names := strings.Split(fe.StructNamespace(), ".")
+	keys := make([]string, 0, len(names))
+	// 第一段为结构体名
+	for i, name := range names[1:] {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return fe.Field()
+		}
+		// 切片等元素的下标，如 Items[0]
+		suffix := ""
+		if pos := strings.Index(name, "["); pos >= 0 {
+			name, suffix = name[:pos], name[pos:]
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return fe.Field()
+		}
+		t = f.Type
+		if i < len(names)-2 && f.Anonymous && f.Tag.Get("json") == "" {
+			continue
+		}
+		key := model.NewJson(f).Name
+		if key == "" {
+			return fe.Field()
+		}
+		keys = append(keys, key+suffix)
+	}
+	return strings.Join(keys, ".")
+}
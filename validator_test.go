@@ -3,6 +3,7 @@ package restful
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/go-playground/validator/v10"
@@ -37,3 +38,40 @@ func TestValidator(t *testing.T) {
 	}
 
 }
+
+type VaBase struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type VaAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type VaJson struct {
+	VaBase
+	UserName string    `json:"userName" validate:"required"`
+	Address  VaAddress `gorm:"embedded" json:"address"`
+	Remark   string    `validate:"required"`
+}
+
+func TestValidatorFieldError(t *testing.T) {
+	valid := Validator{
+		Validator: validator.New(),
+	}
+	err := valid.Validate(context.Background(), &VaJson{})
+	if err == nil {
+		t.Fatalf("Validator.Validate should fail")
+	}
+	details, ok := err.Details.([]*FieldError)
+	if !ok {
+		t.Fatalf("Validator.Validate details fail, got=%v", err.Details)
+	}
+	fields := make([]string, 0, len(details))
+	for _, d := range details {
+		fields = append(fields, d.Field)
+	}
+	expected := []string{"code", "userName", "address.city", "Remark"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("FieldError.Field fail, expect=%v got=%v", expected, fields)
+	}
+}
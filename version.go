@@ -0,0 +1,137 @@
+package restful
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful/jsonpatch"
+	"github.com/lookupearth/restful/model"
+	"github.com/lookupearth/restful/response"
+)
+
+// VersionHeader 通过请求头指定接口版本，如 Accept-Version: v2
+const VersionHeader = "Accept-Version"
+
+// Version 接口版本，路由为 /<Name>/<resource>，或在不带版本前缀的路由上通过 Accept-Version 指定
+type Version struct {
+	Name string
+	// Deprecated 已废弃，响应 Deprecation 头
+	Deprecated bool
+	// Sunset 下线时间，非零时响应 Sunset 头
+	Sunset time.Time
+	// Link 迁移说明地址，废弃时响应 Link 头
+	Link string
+}
+
+// setHeaders 设置版本相关响应头
+func (v *Version) setHeaders(c *gin.Context) {
+	c.Header("Content-Version", v.Name)
+	if v.Deprecated {
+		c.Header("Deprecation", "true")
+		if v.Link != "" {
+			c.Header("Link", "<"+v.Link+">; rel=\"deprecation\"")
+		}
+	}
+	if !v.Sunset.IsZero() {
+		c.Header("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// String 版本描述，用于路由打印
+func (v *Version) String() string {
+	s := v.Name
+	if v.Deprecated {
+		s += "(deprecated)"
+	}
+	if !v.Sunset.IsZero() {
+		s += "(sunset " + v.Sunset.UTC().Format(time.RFC3339) + ")"
+	}
+	return s
+}
+
+// ResourceVersion Resource 在某个接口版本下的输入输出设置，转换的目标均为 Model 的json数据
+type ResourceVersion struct {
+	// Input 输入模型，请求数据按 from tag 反向映射为 Model 的json字段
+	Input *model.Output
+	// Output 输出模型，优先于 Resource 的输出模型
+	Output *model.Output
+	// Request 请求数据转换，在 Input 映射之后调用
+	Request func(*gin.Context, map[string]interface{}) (map[string]interface{}, error)
+	// Response 返回数据转换，在输出模型之后调用，data 为json对应的 map/slice 结构
+	Response func(*gin.Context, interface{}) (interface{}, error)
+}
+
+// transformRequest 转换json对象请求数据，其他格式（如 JSON Patch、CSV）原样返回
+func (v *ResourceVersion) transformRequest(c *gin.Context, body []byte) ([]byte, error) {
+	if (v.Input == nil && v.Request == nil) || len(body) == 0 {
+		return body, nil
+	}
+	contentType := c.ContentType()
+	if contentType != "" && contentType != "application/json" && contentType != jsonpatch.ContentTypeMergePatch {
+		return body, nil
+	}
+	data, err := jsonpatch.Decode(body)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return body, nil
+	}
+	if v.Input != nil {
+		obj = v.Input.Unmap(obj)
+	}
+	if v.Request != nil {
+		obj, err = v.Request(c, obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(obj)
+}
+
+// transformResponse 转换返回数据
+func (v *ResourceVersion) transformResponse(c *gin.Context, data interface{}) (interface{}, error) {
+	if v.Response == nil || data == nil {
+		return data, nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	value, err := jsonpatch.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	return v.Response(c, value)
+}
+
+// versionHandler 设置请求版本与响应头
+func versionHandler(v *Version, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ContextWithVersion(c, v.Name)
+		v.setHeaders(c)
+		handler(c)
+	}
+}
+
+// negotiateHandler 根据 Accept-Version 请求头选择版本，未指定时使用默认版本
+func negotiateHandler(defaultVersion string, handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", VersionHeader)
+		name := c.GetHeader(VersionHeader)
+		if name == "" {
+			name = defaultVersion
+		}
+		handler, ok := handlers[name]
+		if !ok {
+			response.CodeNotAcceptable.New("unsupported version " + strconv.Quote(name)).Response(c)
+			return
+		}
+		handler(c)
+	}
+}
@@ -0,0 +1,92 @@
+package restful
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful/response"
+)
+
+type VersionInput struct {
+	Title string `json:"title" from:"name"`
+}
+
+// echo 返回转换后的请求数据与当前版本
+func (d *DemoResource) echo(c *gin.Context) Response {
+	body, _ := io.ReadAll(c.Request.Body)
+	var data map[string]interface{}
+	_ = json.Unmarshal(body, &data)
+	data["version"] = VersionFromContext(c)
+	return &response.Response{Data: data}
+}
+
+func newVersionDemo() *DemoResource {
+	demo := &DemoResource{
+		Resource: NewResource(&DemoTable{}),
+	}
+	demo.RegisterMethod(ListMethod, HTTPMethodPost, "echo", demo.echo)
+	demo.SetVersionInputModel("v1", &VersionInput{})
+	demo.Version("v1").Response = func(c *gin.Context, data interface{}) (interface{}, error) {
+		obj := data.(map[string]interface{})
+		obj["title"] = obj["name"]
+		delete(obj, "name")
+		return obj, nil
+	}
+	return demo
+}
+
+func TestVersion(t *testing.T) {
+	app := gin.New()
+	root := New()
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	root.AddVersion(&Version{Name: "v1", Deprecated: true, Sunset: sunset}, &Version{Name: "v2"})
+	root.RegisterResource("/demo", newVersionDemo())
+	root.Mount(app.Group("/api"))
+	root.Print("/api")
+
+	cases := []struct {
+		path, header, body, expected, sunset string
+	}{
+		{"/api/v1/demo/echo", "", `{"title":"a"}`, `{"title":"a","version":"v1"}`, "Tue, 01 Jan 2030 00:00:00 GMT"},
+		{"/api/v2/demo/echo", "", `{"name":"a"}`, `{"name":"a","version":"v2"}`, ""},
+		{"/api/demo/echo", "", `{"title":"a"}`, `{"title":"a","version":"v1"}`, "Tue, 01 Jan 2030 00:00:00 GMT"},
+		{"/api/demo/echo", "v2", `{"name":"a"}`, `{"name":"a","version":"v2"}`, ""},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, c.path, strings.NewReader(c.body))
+		req.Header.Set("Content-Type", "application/json")
+		if c.header != "" {
+			req.Header.Set(VersionHeader, c.header)
+		}
+		app.ServeHTTP(w, req)
+		var res struct {
+			Data json.RawMessage `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &res)
+		if string(res.Data) != c.expected {
+			t.Errorf("%s %s fail, got=%s", c.path, c.header, w.Body.String())
+		}
+		if w.Header().Get("Sunset") != c.sunset {
+			t.Errorf("%s %s Sunset fail, got=%s", c.path, c.header, w.Header().Get("Sunset"))
+		}
+		if (c.sunset != "") != (w.Header().Get("Deprecation") == "true") {
+			t.Errorf("%s %s Deprecation fail", c.path, c.header)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/demo/echo", strings.NewReader(`{}`))
+	req.Header.Set(VersionHeader, "v3")
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("unknown version fail, code=%d", w.Code)
+	}
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultDeny 默认拒绝的投递地址：回环、内网、链路本地（含云厂商元数据地址）与未指定地址
+var DefaultDeny = []string{
+	"localhost",
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// HostFilter 投递地址的 host 过滤，在建立连接前检查，避免订阅地址指向内部服务
+//
+//	Allow/Deny 的元素为域名（支持 *.example.com）、IP 或 CIDR，Deny 优先，Allow 不为空时只允许命中的地址。
+//	域名按 URL 中的 host 匹配，IP 与 CIDR 按解析后实际连接的地址匹配，重定向同样会被检查。
+type HostFilter struct {
+	Allow []string
+	Deny  []string
+}
+
+// Check 检查 host 与实际连接的 ip 是否允许投递，ip 为nil时只检查 host
+func (f *HostFilter) Check(host string, ip net.IP) error {
+	if f == nil {
+		return nil
+	}
+	if matchHost(f.Deny, host, ip) {
+		return fmt.Errorf("webhook host %s is denied", addrString(host, ip))
+	}
+	if len(f.Allow) > 0 && !matchHost(f.Allow, host, ip) {
+		return fmt.Errorf("webhook host %s is not allowed", addrString(host, ip))
+	}
+	return nil
+}
+
+func matchHost(patterns []string, host string, ip net.IP) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if _, cidr, err := net.ParseCIDR(pattern); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if p := net.ParseIP(pattern); p != nil {
+			if ip != nil && p.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		if strings.HasPrefix(pattern, "*.") {
+			if strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func addrString(host string, ip net.IP) string {
+	if ip == nil || host == ip.String() {
+		return host
+	}
+	return fmt.Sprintf("%s(%s)", host, ip)
+}
+
+// Transport 在建立连接前按 Hosts 检查地址的 Transport，自定义 Client 时需使用
+//
+//	不使用代理，经代理时无法检查实际连接的地址
+func (s *Service) Transport() *http.Transport {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		d := *dialer
+		// 域名解析后逐个检查实际连接的地址
+		d.Control = func(network string, address string, _ syscall.RawConn) error {
+			ip, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			return s.Hosts.Check(host, net.ParseIP(ip))
+		}
+		return d.DialContext(ctx, network, addr)
+	}
+	return t
+}
@@ -0,0 +1,77 @@
+// Package webhook 资源变更的 webhook 订阅与投递
+package webhook
+
+import (
+	"path"
+	"strings"
+
+	"github.com/lookupearth/restful/field"
+)
+
+const (
+	TableSubscription = "restful_webhook"
+	TableDelivery     = "restful_webhook_delivery"
+)
+
+// 投递状态
+const (
+	DeliveryPending int32 = 0
+	DeliverySuccess int32 = 1
+	DeliveryFailed  int32 = 2
+)
+
+// Subscription webhook 订阅
+//
+//	URL 只支持 http/https；Events 为逗号分隔的事件主题，支持通配符，如 "order.created,user.*"，为空表示全部
+type Subscription struct {
+	ID         int64           `gorm:"column:id;primaryKey;autoIncrement;->" json:"id"`
+	URL        string          `gorm:"column:url;size:1024" json:"url" validate:"required,http_url"`
+	Secret     string          `gorm:"column:secret;size:255" json:"secret" validate:"required,min=16"`
+	Events     string          `gorm:"column:events;size:1024" json:"events"`
+	Active     bool            `gorm:"column:active" json:"active" default:"true"`
+	CreateTime field.Timestamp `gorm:"column:create_time;type:timestamp;autoCreateTime" json:"create_time,readonly"`
+	UpdateTime field.Timestamp `gorm:"column:update_time;type:timestamp;autoUpdateTime" json:"update_time,readonly"`
+}
+
+func (*Subscription) TableName() string {
+	return TableSubscription
+}
+
+// Match 判断事件主题是否命中订阅
+func (s *Subscription) Match(topic string) bool {
+	if len(strings.TrimSpace(s.Events)) == 0 {
+		return true
+	}
+	for _, pattern := range strings.Split(s.Events, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if len(pattern) == 0 {
+			continue
+		}
+		if ok, _ := path.Match(pattern, topic); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery 投递记录，每个事件对每个订阅一条，记录最后一次尝试的结果
+type Delivery struct {
+	ID             int64           `gorm:"column:id;primaryKey;autoIncrement;->" json:"id"`
+	SubscriptionID int64           `gorm:"column:subscription_id;index;uniqueIndex:idx_delivery_event_subscription,priority:2" json:"subscription_id"`
+	EventID        string          `gorm:"column:event_id;size:64;uniqueIndex:idx_delivery_event_subscription,priority:1" json:"event_id"`
+	Topic          string          `gorm:"column:topic;size:255" json:"topic"`
+	URL            string          `gorm:"column:url;size:1024" json:"url"`
+	Payload        field.JSON      `gorm:"column:payload" json:"payload"`
+	Status         int32           `gorm:"column:status;index" json:"status"`
+	Attempts       int32           `gorm:"column:attempts" json:"attempts"`
+	ResponseCode   int32           `gorm:"column:response_code" json:"response_code"`
+	ResponseBody   string          `gorm:"column:response_body;size:1024" json:"response_body"`
+	LastError      string          `gorm:"column:last_error;size:1024" json:"last_error"`
+	Duration       int64           `gorm:"column:duration" json:"duration"`
+	CreateTime     field.Timestamp `gorm:"column:create_time;type:timestamp" json:"create_time"`
+	UpdateTime     field.Timestamp `gorm:"column:update_time;type:timestamp" json:"update_time"`
+}
+
+func (*Delivery) TableName() string {
+	return TableDelivery
+}
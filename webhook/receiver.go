@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lookupearth/restful/event"
+)
+
+// Received 接收到的一次投递
+type Received struct {
+	Header http.Header
+	Body   []byte
+	Event  *event.Event
+	// Valid 签名是否正确
+	Valid bool
+}
+
+// Receiver 本地测试用的接收端，校验签名并记录投递内容，配合 httptest.NewServer 使用
+type Receiver struct {
+	Secret string
+	// Status 返回的 http 状态码，默认200，可用于模拟失败
+	Status int
+
+	mu       sync.Mutex
+	received []*Received
+	notify   chan struct{}
+}
+
+func NewReceiver(secret string) *Receiver {
+	return &Receiver{
+		Secret: secret,
+		Status: http.StatusOK,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	received := &Received{
+		Header: req.Header.Clone(),
+		Body:   body,
+		Valid:  Verify(r.Secret, req.Header.Get(HeaderTimestamp), body, req.Header.Get(HeaderSignature)),
+	}
+	e := &event.Event{}
+	if err := json.Unmarshal(body, e); err == nil {
+		received.Event = e
+	}
+
+	r.mu.Lock()
+	r.received = append(r.received, received)
+	status := r.Status
+	r.mu.Unlock()
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+
+	if !received.Valid {
+		status = http.StatusUnauthorized
+	}
+	w.WriteHeader(status)
+}
+
+// SetStatus 修改返回的 http 状态码
+func (r *Receiver) SetStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Status = status
+}
+
+// Received 已接收的投递
+func (r *Receiver) Received() []*Received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	received := make([]*Received, len(r.received))
+	copy(received, r.received)
+	return received
+}
+
+// Wait 等待至少接收 n 次投递，超时返回 false
+func (r *Receiver) Wait(n int, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		if len(r.Received()) >= n {
+			return true
+		}
+		select {
+		case <-r.notify:
+		case <-deadline:
+			return false
+		}
+	}
+}
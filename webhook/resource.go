@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/mixins"
+	"gorm.io/gorm"
+)
+
+// SubscriptionResource webhook 订阅资源，secret 只写不读
+type SubscriptionResource struct {
+	*restful.Resource
+	*mixins.GetMethod
+	*mixins.ListMethod
+	*mixins.PostMethod
+	*mixins.PutMethod
+	*mixins.PatchMethod
+	*mixins.DeleteMethod
+}
+
+type subscriptionSearch struct {
+	URL    string `gorm:"column:url" json:"url" operate:"like"`
+	Active bool   `gorm:"column:active" json:"active"`
+}
+
+func NewSubscriptionResource(db *gorm.DB) *SubscriptionResource {
+	return &SubscriptionResource{
+		Resource:  restful.NewResourceWithDB(db, &Subscription{}),
+		GetMethod: &mixins.GetMethod{},
+		ListMethod: &mixins.ListMethod{
+			Limit:        20,
+			OrderBy:      []string{"id desc"},
+			SearchParams: &subscriptionSearch{},
+		},
+		PostMethod:   &mixins.PostMethod{},
+		PutMethod:    &mixins.PutMethod{},
+		PatchMethod:  &mixins.PatchMethod{},
+		DeleteMethod: &mixins.DeleteMethod{},
+	}
+}
+
+func (r *SubscriptionResource) GetAfter(ctx *gin.Context, data interface{}) (interface{}, error) {
+	data.(*Subscription).Secret = ""
+	return data, nil
+}
+
+func (r *SubscriptionResource) ListAfter(ctx *gin.Context, data interface{}) (interface{}, error) {
+	subscriptions := *data.(*[]Subscription)
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return data, nil
+}
+
+// DeliveryResource 投递记录资源，只读
+type DeliveryResource struct {
+	*restful.Resource
+	*mixins.GetMethod
+	*mixins.ListMethod
+}
+
+type deliverySearch struct {
+	SubscriptionID int64  `gorm:"column:subscription_id" json:"subscription_id"`
+	EventID        string `gorm:"column:event_id" json:"event_id"`
+	Topic          string `gorm:"column:topic" json:"topic"`
+	Status         int32  `gorm:"column:status" json:"status"`
+}
+
+func NewDeliveryResource(db *gorm.DB) *DeliveryResource {
+	return &DeliveryResource{
+		Resource:  restful.NewResourceWithDB(db, &Delivery{}),
+		GetMethod: &mixins.GetMethod{},
+		ListMethod: &mixins.ListMethod{
+			Limit:        20,
+			OrderBy:      []string{"id desc"},
+			SearchParams: &deliverySearch{},
+		},
+	}
+}
+
+// Register 注册订阅资源 <prefix>/webhooks 与投递记录资源 <prefix>/webhook_deliveries
+func (s *Service) Register(root restful.IRoot, prefix string) {
+	root.RegisterResource(prefix+"/webhooks", NewSubscriptionResource(s.DB))
+	root.RegisterResource(prefix+"/webhook_deliveries", NewDeliveryResource(s.DB))
+}
@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lookupearth/restful/event"
+	"github.com/lookupearth/restful/field"
+	"gorm.io/gorm"
+)
+
+const maxLogLength = 1024
+
+// Service webhook 服务，实现 event.Publisher，由 event.Dispatcher 驱动投递
+//
+//	资源通过 event.Outbox 在写事务内记录事件，Dispatcher 投递时向命中的订阅发送请求，每个订阅一条投递记录。
+//	部分订阅失败时返回错误，由 Dispatcher 按退避策略重试，已成功的订阅不会重复发送；
+//	单个订阅尝试超过 MaxAttempts 后标记失败，MaxAttempts 不应大于 Dispatcher.MaxAttempts。
+//	只投递 http/https 地址，连接前按 Hosts 检查地址，自定义 Client 时需使用 Service.Transport。
+type Service struct {
+	DB          *gorm.DB
+	Client      *http.Client
+	MaxAttempts int
+	// Hosts 投递地址的 host 过滤，默认拒绝 DefaultDeny，为nil时不限制
+	Hosts *HostFilter
+	// OnError 投递失败时回调，可用于记录日志
+	OnError func(error)
+}
+
+func New(db *gorm.DB) *Service {
+	s := &Service{
+		DB:          db,
+		MaxAttempts: 8,
+		Hosts:       &HostFilter{Deny: DefaultDeny},
+	}
+	s.Client = &http.Client{Timeout: 10 * time.Second, Transport: s.Transport()}
+	return s
+}
+
+// AutoMigrate 创建订阅表与投递记录表
+func (s *Service) AutoMigrate() error {
+	return s.DB.AutoMigrate(&Subscription{}, &Delivery{})
+}
+
+// NewDispatcher 创建投递 outbox 事件的 Dispatcher，重试次数与 Service 一致，退避从10秒开始，最长1小时
+func (s *Service) NewDispatcher(outbox *event.Outbox) *event.Dispatcher {
+	d := event.NewDispatcher(outbox, s)
+	d.MaxAttempts = s.MaxAttempts
+	d.Backoff = event.ExponentialBackoff(10*time.Second, time.Hour)
+	d.OnError = s.OnError
+	return d
+}
+
+// Publish 实现 event.Publisher，向事件命中且尚未投递成功的订阅发送请求
+func (s *Service) Publish(ctx context.Context, e *event.Event) error {
+	db := s.DB.WithContext(ctx)
+	subscriptions := make([]*Subscription, 0)
+	if err := db.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	deliveries, err := s.prepare(db, e, subscriptions)
+	if err != nil {
+		return err
+	}
+	subMap := make(map[int64]*Subscription, len(subscriptions))
+	for _, sub := range subscriptions {
+		subMap[sub.ID] = sub
+	}
+	// 请求发出后即使 ctx 取消也记录结果
+	saveDB := s.DB.WithContext(context.WithoutCancel(ctx))
+	pending := 0
+	for _, d := range deliveries {
+		if d.Status != DeliveryPending {
+			continue
+		}
+		if sub, ok := subMap[d.SubscriptionID]; ok {
+			s.Deliver(ctx, sub, d)
+		} else {
+			d.Attempts++
+			d.Status = DeliveryFailed
+			d.LastError = "subscription not found or inactive"
+		}
+		if err := s.save(saveDB, d); err != nil {
+			return err
+		}
+		if d.Status == DeliveryPending {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("webhook event %s has %d failed deliveries", e.ID, pending)
+	}
+	return nil
+}
+
+// prepare 为命中事件且没有投递记录的订阅创建投递记录，返回事件的全部投递记录
+func (s *Service) prepare(db *gorm.DB, e *event.Event, subscriptions []*Subscription) ([]*Delivery, error) {
+	deliveries := make([]*Delivery, 0)
+	if err := db.Where("event_id = ?", e.ID).Order("id").Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	exists := make(map[int64]bool, len(deliveries))
+	for _, d := range deliveries {
+		exists[d.SubscriptionID] = true
+	}
+	topic := e.Topic()
+	created := make([]*Delivery, 0)
+	for _, sub := range subscriptions {
+		if exists[sub.ID] || !sub.Match(topic) {
+			continue
+		}
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		now := field.Timestamp(time.Now())
+		created = append(created, &Delivery{
+			SubscriptionID: sub.ID,
+			EventID:        e.ID,
+			Topic:          topic,
+			URL:            sub.URL,
+			Payload:        payload,
+			Status:         DeliveryPending,
+			CreateTime:     now,
+			UpdateTime:     now,
+		})
+	}
+	if len(created) == 0 {
+		return deliveries, nil
+	}
+	if err := db.Create(&created).Error; err != nil {
+		return nil, err
+	}
+	// 重新读取以获得投递记录的 id
+	deliveries = deliveries[:0]
+	err := db.Where("event_id = ?", e.ID).Order("id").Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (s *Service) save(db *gorm.DB, d *Delivery) error {
+	d.UpdateTime = field.Timestamp(time.Now())
+	return db.Model(&Delivery{}).Where("id = ?", d.ID).Updates(map[string]interface{}{
+		"status":        d.Status,
+		"attempts":      d.Attempts,
+		"response_code": d.ResponseCode,
+		"response_body": d.ResponseBody,
+		"last_error":    d.LastError,
+		"duration":      d.Duration,
+		"update_time":   d.UpdateTime,
+	}).Error
+}
+
+// Deliver 向订阅地址发送一次请求，结果记录到 d 中，失败且未超过 MaxAttempts 时保持待投递状态
+func (s *Service) Deliver(ctx context.Context, sub *Subscription, d *Delivery) {
+	d.Attempts++
+	d.ResponseCode = 0
+	d.ResponseBody = ""
+	d.LastError = ""
+	start := time.Now()
+	code, body, err := s.send(ctx, sub, d)
+	d.Duration = time.Since(start).Milliseconds()
+	d.ResponseCode = int32(code)
+	d.ResponseBody = truncate(body)
+	if err == nil && (code < 200 || code > 299) {
+		err = fmt.Errorf("response status %d", code)
+	}
+	if err == nil {
+		d.Status = DeliverySuccess
+		return
+	}
+	d.LastError = truncate(err.Error())
+	s.error(fmt.Errorf("webhook delivery %d to %s failed: %w", d.ID, d.URL, err))
+	if s.MaxAttempts > 0 && int(d.Attempts) >= s.MaxAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+	d.Status = DeliveryPending
+}
+
+func (s *Service) send(ctx context.Context, sub *Subscription, d *Delivery) (int, string, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return 0, "", fmt.Errorf("unsupported webhook url scheme %s", req.URL.Scheme)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.Topic)
+	req.Header.Set(HeaderEventID, d.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, body))
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Transport: s.Transport()}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxLogLength))
+	return resp.StatusCode, string(respBody), nil
+}
+
+func (s *Service) error(err error) {
+	if s.OnError != nil && !errors.Is(err, context.Canceled) {
+		s.OnError(err)
+	}
+}
+
+func truncate(s string) string {
+	if len(s) > maxLogLength {
+		return s[:maxLogLength]
+	}
+	return s
+}
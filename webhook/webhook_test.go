@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/lookupearth/restful"
+	"github.com/lookupearth/restful/event"
+)
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	signature := Sign("0123456789abcdef", "1700000000", body)
+	if !Verify("0123456789abcdef", "1700000000", body, signature) {
+		t.Errorf("Verify fail, signature=%s", signature)
+	}
+	if Verify("0123456789abcdef", "1700000001", body, signature) {
+		t.Errorf("Verify expect fail with other timestamp")
+	}
+	if Verify("other-secret-0000", "1700000000", body, signature) {
+		t.Errorf("Verify expect fail with other secret")
+	}
+}
+
+func TestSubscriptionMatch(t *testing.T) {
+	cases := []struct {
+		Events string
+		Topic  string
+		Match  bool
+	}{
+		{"", "order.created", true},
+		{"order.created", "order.created", true},
+		{"order.created", "order.updated", false},
+		{"order.*", "order.deleted", true},
+		{"user.*, order.updated", "order.updated", true},
+		{"user.*", "order.updated", false},
+		{"*", "order.updated", true},
+	}
+	for _, c := range cases {
+		sub := &Subscription{Events: c.Events}
+		if sub.Match(c.Topic) != c.Match {
+			t.Errorf("Subscription.Match fail, events=%s topic=%s", c.Events, c.Topic)
+		}
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	secret := "0123456789abcdef"
+	receiver := NewReceiver(secret)
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	e := event.NewEvent("order", event.ActionCreate, 1)
+	payload, _ := json.Marshal(e)
+	sub := &Subscription{ID: 1, URL: server.URL, Secret: secret, Active: true}
+	s := New(nil)
+	s.MaxAttempts = 2
+	// 测试服务监听在回环地址
+	s.Hosts = &HostFilter{Allow: []string{"127.0.0.1"}}
+
+	d := &Delivery{ID: 1, SubscriptionID: 1, EventID: e.ID, Topic: e.Topic(), URL: server.URL, Payload: payload}
+	s.Deliver(context.Background(), sub, d)
+	if d.Status != DeliverySuccess || d.Attempts != 1 || d.ResponseCode != http.StatusOK {
+		t.Errorf("Deliver fail, status=%d attempts=%d code=%d error=%s", d.Status, d.Attempts, d.ResponseCode, d.LastError)
+	}
+	if !receiver.Wait(1, time.Second) {
+		t.Fatalf("Receiver.Wait timeout")
+	}
+	received := receiver.Received()[0]
+	if !received.Valid || received.Event == nil || received.Event.ID != e.ID {
+		t.Errorf("Receiver fail, valid=%v", received.Valid)
+	}
+	if received.Header.Get(HeaderEvent) != "order.created" {
+		t.Errorf("Receiver header fail, got=%s", received.Header.Get(HeaderEvent))
+	}
+
+	receiver.SetStatus(http.StatusServiceUnavailable)
+	d2 := &Delivery{ID: 2, SubscriptionID: 1, EventID: e.ID, Topic: e.Topic(), URL: server.URL, Payload: payload}
+	s.Deliver(context.Background(), sub, d2)
+	if d2.Status != DeliveryPending || d2.ResponseCode != http.StatusServiceUnavailable {
+		t.Errorf("Deliver retry fail, status=%d code=%d", d2.Status, d2.ResponseCode)
+	}
+	s.Deliver(context.Background(), sub, d2)
+	if d2.Status != DeliveryFailed || d2.Attempts != 2 {
+		t.Errorf("Deliver max attempts fail, status=%d attempts=%d", d2.Status, d2.Attempts)
+	}
+
+	wrongSecret := &Subscription{ID: 1, URL: server.URL, Secret: "other-secret-0000", Active: true}
+	receiver.SetStatus(http.StatusOK)
+	d3 := &Delivery{ID: 3, SubscriptionID: 1, EventID: e.ID, Topic: e.Topic(), URL: server.URL, Payload: payload}
+	s.Deliver(context.Background(), wrongSecret, d3)
+	if d3.ResponseCode != http.StatusUnauthorized {
+		t.Errorf("Receiver signature check fail, code=%d", d3.ResponseCode)
+	}
+}
+
+func TestHostFilter(t *testing.T) {
+	cases := []struct {
+		Filter *HostFilter
+		Host   string
+		IP     string
+		Allow  bool
+	}{
+		{nil, "127.0.0.1", "127.0.0.1", true},
+		{&HostFilter{Deny: DefaultDeny}, "example.com", "93.184.216.34", true},
+		{&HostFilter{Deny: DefaultDeny}, "example.com", "10.1.2.3", false},
+		{&HostFilter{Deny: DefaultDeny}, "metadata", "169.254.169.254", false},
+		{&HostFilter{Deny: DefaultDeny}, "LocalHost.", "", false},
+		{&HostFilter{Deny: DefaultDeny}, "v6", "::ffff:127.0.0.1", false},
+		{&HostFilter{Allow: []string{"*.example.com"}}, "hooks.example.com", "93.184.216.34", true},
+		{&HostFilter{Allow: []string{"*.example.com"}}, "example.org", "93.184.216.34", false},
+		{&HostFilter{Allow: []string{"hooks.example.com"}, Deny: []string{"93.184.216.0/24"}}, "hooks.example.com", "93.184.216.34", false},
+	}
+	for _, c := range cases {
+		err := c.Filter.Check(c.Host, net.ParseIP(c.IP))
+		if (err == nil) != c.Allow {
+			t.Errorf("HostFilter.Check fail, host=%s ip=%s error=%v", c.Host, c.IP, err)
+		}
+	}
+}
+
+func TestDeliverDenied(t *testing.T) {
+	receiver := NewReceiver("0123456789abcdef")
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	s := New(nil)
+	for _, url := range []string{server.URL, "ftp://example.com/hook", "file:///etc/passwd"} {
+		sub := &Subscription{ID: 1, URL: url, Secret: "0123456789abcdef", Active: true}
+		d := &Delivery{ID: 1, SubscriptionID: 1, URL: url, Payload: []byte(`{}`)}
+		s.Deliver(context.Background(), sub, d)
+		if d.Status != DeliveryPending || d.ResponseCode != 0 || d.LastError == "" {
+			t.Errorf("Deliver %s should be denied, status=%d code=%d", url, d.Status, d.ResponseCode)
+		}
+	}
+	if len(receiver.Received()) != 0 {
+		t.Errorf("denied delivery should not reach receiver")
+	}
+	// 订阅地址只支持 http/https
+	if err := validator.New().Struct(&Subscription{URL: "ftp://example.com/hook", Secret: "0123456789abcdef"}); err == nil {
+		t.Errorf("Subscription url should be http or https")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	app := gin.New()
+	root := restful.New()
+	New(nil).Register(root, "/admin")
+	root.Mount(app.Group("/api"))
+	routes := make(map[string]bool)
+	for _, route := range app.Routes() {
+		routes[route.Path] = true
+	}
+	for _, path := range []string{"/api/admin/webhooks", "/api/admin/webhooks/:id", "/api/admin/webhook_deliveries/:id"} {
+		if !routes[path] {
+			t.Errorf("route %s not mounted", path)
+		}
+	}
+}